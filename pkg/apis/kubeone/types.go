@@ -19,7 +19,9 @@ package kubeone
 import (
 	"encoding/json"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -40,6 +42,8 @@ type KubeOneCluster struct {
 	Versions VersionConfig `json:"versions,omitempty"`
 	// ClusterNetwork configures the in-cluster networking
 	ClusterNetwork ClusterNetworkConfig `json:"clusterNetwork,omitempty"`
+	// ComponentConfig configures the Kubernetes control plane components
+	ComponentConfig ComponentConfig `json:"componentConfig,omitempty"`
 	// Proxy configures proxy used while installing Kubernetes and by the Docker daemon
 	Proxy ProxyConfig `json:"proxy,omitempty"`
 	// Workers is used to create worker nodes using the Kubermatic machine-controller
@@ -61,6 +65,23 @@ type HostConfig struct {
 	SSHUsername       string `json:"sshUsername"`
 	SSHPrivateKeyFile string `json:"sshPrivateKeyFile"`
 	SSHAgentSocket    string `json:"sshAgentSocket"`
+	SSHHostPublicKey  string `json:"sshHostPublicKey,omitempty"`
+	// Labels are applied to the Node object of the host
+	Labels map[string]string `json:"labels,omitempty"`
+	// Taints are applied to the Node object of the host instead of the default
+	// control plane taint
+	Taints []corev1.Taint `json:"taints,omitempty"`
+	// KubeletExtraArgs are passed to the kubelet of the host and take
+	// precedence over the flags set by KubeOne
+	KubeletExtraArgs map[string]string `json:"kubeletExtraArgs,omitempty"`
+	// Annotations are arbitrary metadata about the host, such as the rack ID
+	// or maintenance window. Unlike Labels, they are not applied to the Node
+	// object
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// PrivateNetworkGateway is the gateway for the private network traffic
+	// of the host. It is informational only, KubeOne doesn't configure any
+	// routes using it yet
+	PrivateNetworkGateway string `json:"privateNetworkGateway,omitempty"`
 
 	// Information populated at the runtime
 	Hostname        string `json:"-"`
@@ -75,6 +96,15 @@ type APIEndpoint struct {
 
 	// Port is the port used to reach to the API
 	Port int `json:"port"`
+
+	// AlternativeNames is a list of additional names added to the API server
+	// certificate SANs
+	AlternativeNames []string `json:"alternativeNames,omitempty"`
+
+	// InternalHost is the hostname of an internal load balancer used by the
+	// nodes and in-cluster components to reach the API, while Host is only
+	// used by KubeOne itself. Defaults to Host
+	InternalHost string `json:"internalHost,omitempty"`
 }
 
 // CloudProviderName represents the name of a provider
@@ -98,6 +128,10 @@ type CloudProviderSpec struct {
 	Name        CloudProviderName `json:"name"`
 	External    bool              `json:"external"`
 	CloudConfig string            `json:"cloudConfig"`
+	// CCMVersion pins the version of the external cloud controller manager
+	// A pinned version is deployed even if a newer version is already running
+	// Defaults to the version bundled with KubeOne
+	CCMVersion string `json:"ccmVersion,omitempty"`
 }
 
 // VersionConfig describes the versions of components that are installed on the machines
@@ -144,6 +178,30 @@ type CNI struct {
 	Encrypted bool `json:"encrypted"`
 }
 
+// ComponentConfig configures the Kubernetes control plane components
+type ComponentConfig struct {
+	APIServer         ControlPlaneComponentConfig `json:"apiServer"`
+	ControllerManager ControlPlaneComponentConfig `json:"controllerManager"`
+	Etcd              EtcdComponentConfig         `json:"etcd"`
+}
+
+// ControlPlaneComponentConfig configures a single control plane component
+type ControlPlaneComponentConfig struct {
+	// ExtraArgs are additional flags passed to the component. They take
+	// precedence over the flags set by KubeOne.
+	ExtraArgs map[string]string `json:"extraArgs,omitempty"`
+}
+
+// EtcdComponentConfig configures the local etcd members
+type EtcdComponentConfig struct {
+	// DataDir is the directory etcd stores its data in on the control plane
+	// hosts, e.g. a dedicated volume. Defaults to /var/lib/etcd
+	DataDir string `json:"dataDir,omitempty"`
+	// CompactionInterval is the interval in which etcd periodically compacts
+	// its key-value history, e.g. 5m. Disabled by default
+	CompactionInterval string `json:"compactionInterval,omitempty"`
+}
+
 // ProxyConfig configures proxy for the Docker daemon and is used by KubeOne scripts
 type ProxyConfig struct {
 	HTTP    string `json:"http"`
@@ -156,6 +214,18 @@ type WorkerConfig struct {
 	Name     string       `json:"name"`
 	Replicas *int         `json:"replicas"`
 	Config   ProviderSpec `json:"providerSpec"`
+	// RollingUpdate configures how the machines of the workerset are replaced
+	RollingUpdate *RollingUpdateConfig `json:"rollingUpdate,omitempty"`
+}
+
+// RollingUpdateConfig configures the rolling update of a workerset
+type RollingUpdateConfig struct {
+	// MaxSurge is the number or percentage of machines created above the
+	// desired replicas during an update. Defaults to 1
+	MaxSurge *intstr.IntOrString `json:"maxSurge,omitempty"`
+	// MaxUnavailable is the number or percentage of machines that can be
+	// unavailable during an update. Defaults to 0
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
 }
 
 // ProviderSpec describes a worker node
@@ -165,6 +235,9 @@ type ProviderSpec struct {
 	SSHPublicKeys       []string          `json:"sshPublicKeys"`
 	OperatingSystem     string            `json:"operatingSystem"`
 	OperatingSystemSpec json.RawMessage   `json:"operatingSystemSpec"`
+	// DiskEncryptionKeyID is the ID of the key used to encrypt the disks of
+	// the machines, it overrides the provider-specific key field
+	DiskEncryptionKeyID string `json:"diskEncryptionKeyID,omitempty"`
 }
 
 // MachineControllerConfig configures kubermatic machine-controller deployment
@@ -173,6 +246,9 @@ type MachineControllerConfig struct {
 	// Provider is provider to be used for machine-controller
 	// Defaults and must be same as chosen cloud provider, unless cloud provider is set to None
 	Provider CloudProviderName `json:"provider"`
+	// Version pins the machine-controller version, e.g. v1.1.9
+	// Defaults to the version bundled with KubeOne
+	Version string `json:"version,omitempty"`
 }
 
 // Features controls what features will be enabled on the cluster
@@ -25,8 +25,10 @@ import (
 	unsafe "unsafe"
 
 	kubeone "github.com/kubermatic/kubeone/pkg/apis/kubeone"
+	v1 "k8s.io/api/core/v1"
 	conversion "k8s.io/apimachinery/pkg/conversion"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	intstr "k8s.io/apimachinery/pkg/util/intstr"
 )
 
 func init() {
@@ -76,6 +78,26 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*ComponentConfig)(nil), (*kubeone.ComponentConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_ComponentConfig_To_kubeone_ComponentConfig(a.(*ComponentConfig), b.(*kubeone.ComponentConfig), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kubeone.ComponentConfig)(nil), (*ComponentConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kubeone_ComponentConfig_To_v1alpha1_ComponentConfig(a.(*kubeone.ComponentConfig), b.(*ComponentConfig), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*ControlPlaneComponentConfig)(nil), (*kubeone.ControlPlaneComponentConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_ControlPlaneComponentConfig_To_kubeone_ControlPlaneComponentConfig(a.(*ControlPlaneComponentConfig), b.(*kubeone.ControlPlaneComponentConfig), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kubeone.ControlPlaneComponentConfig)(nil), (*ControlPlaneComponentConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kubeone_ControlPlaneComponentConfig_To_v1alpha1_ControlPlaneComponentConfig(a.(*kubeone.ControlPlaneComponentConfig), b.(*ControlPlaneComponentConfig), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*DynamicAuditLog)(nil), (*kubeone.DynamicAuditLog)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_DynamicAuditLog_To_kubeone_DynamicAuditLog(a.(*DynamicAuditLog), b.(*kubeone.DynamicAuditLog), scope)
 	}); err != nil {
@@ -86,6 +108,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*EtcdComponentConfig)(nil), (*kubeone.EtcdComponentConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_EtcdComponentConfig_To_kubeone_EtcdComponentConfig(a.(*EtcdComponentConfig), b.(*kubeone.EtcdComponentConfig), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kubeone.EtcdComponentConfig)(nil), (*EtcdComponentConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kubeone_EtcdComponentConfig_To_v1alpha1_EtcdComponentConfig(a.(*kubeone.EtcdComponentConfig), b.(*EtcdComponentConfig), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*Features)(nil), (*kubeone.Features)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_Features_To_kubeone_Features(a.(*Features), b.(*kubeone.Features), scope)
 	}); err != nil {
@@ -186,6 +218,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*RollingUpdateConfig)(nil), (*kubeone.RollingUpdateConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_RollingUpdateConfig_To_kubeone_RollingUpdateConfig(a.(*RollingUpdateConfig), b.(*kubeone.RollingUpdateConfig), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kubeone.RollingUpdateConfig)(nil), (*RollingUpdateConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kubeone_RollingUpdateConfig_To_v1alpha1_RollingUpdateConfig(a.(*kubeone.RollingUpdateConfig), b.(*RollingUpdateConfig), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*VersionConfig)(nil), (*kubeone.VersionConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_VersionConfig_To_kubeone_VersionConfig(a.(*VersionConfig), b.(*kubeone.VersionConfig), scope)
 	}); err != nil {
@@ -212,6 +254,8 @@ func RegisterConversions(s *runtime.Scheme) error {
 func autoConvert_v1alpha1_APIEndpoint_To_kubeone_APIEndpoint(in *APIEndpoint, out *kubeone.APIEndpoint, s conversion.Scope) error {
 	out.Host = in.Host
 	out.Port = in.Port
+	out.AlternativeNames = *(*[]string)(unsafe.Pointer(&in.AlternativeNames))
+	out.InternalHost = in.InternalHost
 	return nil
 }
 
@@ -223,6 +267,8 @@ func Convert_v1alpha1_APIEndpoint_To_kubeone_APIEndpoint(in *APIEndpoint, out *k
 func autoConvert_kubeone_APIEndpoint_To_v1alpha1_APIEndpoint(in *kubeone.APIEndpoint, out *APIEndpoint, s conversion.Scope) error {
 	out.Host = in.Host
 	out.Port = in.Port
+	out.AlternativeNames = *(*[]string)(unsafe.Pointer(&in.AlternativeNames))
+	out.InternalHost = in.InternalHost
 	return nil
 }
 
@@ -257,6 +303,7 @@ func autoConvert_v1alpha1_CloudProviderSpec_To_kubeone_CloudProviderSpec(in *Clo
 	out.Name = kubeone.CloudProviderName(in.Name)
 	out.External = in.External
 	out.CloudConfig = in.CloudConfig
+	out.CCMVersion = in.CCMVersion
 	return nil
 }
 
@@ -269,6 +316,7 @@ func autoConvert_kubeone_CloudProviderSpec_To_v1alpha1_CloudProviderSpec(in *kub
 	out.Name = CloudProviderName(in.Name)
 	out.External = in.External
 	out.CloudConfig = in.CloudConfig
+	out.CCMVersion = in.CCMVersion
 	return nil
 }
 
@@ -305,6 +353,62 @@ func Convert_kubeone_ClusterNetworkConfig_To_v1alpha1_ClusterNetworkConfig(in *k
 	return autoConvert_kubeone_ClusterNetworkConfig_To_v1alpha1_ClusterNetworkConfig(in, out, s)
 }
 
+func autoConvert_v1alpha1_ComponentConfig_To_kubeone_ComponentConfig(in *ComponentConfig, out *kubeone.ComponentConfig, s conversion.Scope) error {
+	if err := Convert_v1alpha1_ControlPlaneComponentConfig_To_kubeone_ControlPlaneComponentConfig(&in.APIServer, &out.APIServer, s); err != nil {
+		return err
+	}
+	if err := Convert_v1alpha1_ControlPlaneComponentConfig_To_kubeone_ControlPlaneComponentConfig(&in.ControllerManager, &out.ControllerManager, s); err != nil {
+		return err
+	}
+	if err := Convert_v1alpha1_EtcdComponentConfig_To_kubeone_EtcdComponentConfig(&in.Etcd, &out.Etcd, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha1_ComponentConfig_To_kubeone_ComponentConfig is an autogenerated conversion function.
+func Convert_v1alpha1_ComponentConfig_To_kubeone_ComponentConfig(in *ComponentConfig, out *kubeone.ComponentConfig, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ComponentConfig_To_kubeone_ComponentConfig(in, out, s)
+}
+
+func autoConvert_kubeone_ComponentConfig_To_v1alpha1_ComponentConfig(in *kubeone.ComponentConfig, out *ComponentConfig, s conversion.Scope) error {
+	if err := Convert_kubeone_ControlPlaneComponentConfig_To_v1alpha1_ControlPlaneComponentConfig(&in.APIServer, &out.APIServer, s); err != nil {
+		return err
+	}
+	if err := Convert_kubeone_ControlPlaneComponentConfig_To_v1alpha1_ControlPlaneComponentConfig(&in.ControllerManager, &out.ControllerManager, s); err != nil {
+		return err
+	}
+	if err := Convert_kubeone_EtcdComponentConfig_To_v1alpha1_EtcdComponentConfig(&in.Etcd, &out.Etcd, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_kubeone_ComponentConfig_To_v1alpha1_ComponentConfig is an autogenerated conversion function.
+func Convert_kubeone_ComponentConfig_To_v1alpha1_ComponentConfig(in *kubeone.ComponentConfig, out *ComponentConfig, s conversion.Scope) error {
+	return autoConvert_kubeone_ComponentConfig_To_v1alpha1_ComponentConfig(in, out, s)
+}
+
+func autoConvert_v1alpha1_ControlPlaneComponentConfig_To_kubeone_ControlPlaneComponentConfig(in *ControlPlaneComponentConfig, out *kubeone.ControlPlaneComponentConfig, s conversion.Scope) error {
+	out.ExtraArgs = *(*map[string]string)(unsafe.Pointer(&in.ExtraArgs))
+	return nil
+}
+
+// Convert_v1alpha1_ControlPlaneComponentConfig_To_kubeone_ControlPlaneComponentConfig is an autogenerated conversion function.
+func Convert_v1alpha1_ControlPlaneComponentConfig_To_kubeone_ControlPlaneComponentConfig(in *ControlPlaneComponentConfig, out *kubeone.ControlPlaneComponentConfig, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ControlPlaneComponentConfig_To_kubeone_ControlPlaneComponentConfig(in, out, s)
+}
+
+func autoConvert_kubeone_ControlPlaneComponentConfig_To_v1alpha1_ControlPlaneComponentConfig(in *kubeone.ControlPlaneComponentConfig, out *ControlPlaneComponentConfig, s conversion.Scope) error {
+	out.ExtraArgs = *(*map[string]string)(unsafe.Pointer(&in.ExtraArgs))
+	return nil
+}
+
+// Convert_kubeone_ControlPlaneComponentConfig_To_v1alpha1_ControlPlaneComponentConfig is an autogenerated conversion function.
+func Convert_kubeone_ControlPlaneComponentConfig_To_v1alpha1_ControlPlaneComponentConfig(in *kubeone.ControlPlaneComponentConfig, out *ControlPlaneComponentConfig, s conversion.Scope) error {
+	return autoConvert_kubeone_ControlPlaneComponentConfig_To_v1alpha1_ControlPlaneComponentConfig(in, out, s)
+}
+
 func autoConvert_v1alpha1_DynamicAuditLog_To_kubeone_DynamicAuditLog(in *DynamicAuditLog, out *kubeone.DynamicAuditLog, s conversion.Scope) error {
 	out.Enable = in.Enable
 	return nil
@@ -325,6 +429,28 @@ func Convert_kubeone_DynamicAuditLog_To_v1alpha1_DynamicAuditLog(in *kubeone.Dyn
 	return autoConvert_kubeone_DynamicAuditLog_To_v1alpha1_DynamicAuditLog(in, out, s)
 }
 
+func autoConvert_v1alpha1_EtcdComponentConfig_To_kubeone_EtcdComponentConfig(in *EtcdComponentConfig, out *kubeone.EtcdComponentConfig, s conversion.Scope) error {
+	out.DataDir = in.DataDir
+	out.CompactionInterval = in.CompactionInterval
+	return nil
+}
+
+// Convert_v1alpha1_EtcdComponentConfig_To_kubeone_EtcdComponentConfig is an autogenerated conversion function.
+func Convert_v1alpha1_EtcdComponentConfig_To_kubeone_EtcdComponentConfig(in *EtcdComponentConfig, out *kubeone.EtcdComponentConfig, s conversion.Scope) error {
+	return autoConvert_v1alpha1_EtcdComponentConfig_To_kubeone_EtcdComponentConfig(in, out, s)
+}
+
+func autoConvert_kubeone_EtcdComponentConfig_To_v1alpha1_EtcdComponentConfig(in *kubeone.EtcdComponentConfig, out *EtcdComponentConfig, s conversion.Scope) error {
+	out.DataDir = in.DataDir
+	out.CompactionInterval = in.CompactionInterval
+	return nil
+}
+
+// Convert_kubeone_EtcdComponentConfig_To_v1alpha1_EtcdComponentConfig is an autogenerated conversion function.
+func Convert_kubeone_EtcdComponentConfig_To_v1alpha1_EtcdComponentConfig(in *kubeone.EtcdComponentConfig, out *EtcdComponentConfig, s conversion.Scope) error {
+	return autoConvert_kubeone_EtcdComponentConfig_To_v1alpha1_EtcdComponentConfig(in, out, s)
+}
+
 func autoConvert_v1alpha1_Features_To_kubeone_Features(in *Features, out *kubeone.Features, s conversion.Scope) error {
 	out.PodSecurityPolicy = (*kubeone.PodSecurityPolicy)(unsafe.Pointer(in.PodSecurityPolicy))
 	out.DynamicAuditLog = (*kubeone.DynamicAuditLog)(unsafe.Pointer(in.DynamicAuditLog))
@@ -359,6 +485,12 @@ func autoConvert_v1alpha1_HostConfig_To_kubeone_HostConfig(in *HostConfig, out *
 	out.SSHUsername = in.SSHUsername
 	out.SSHPrivateKeyFile = in.SSHPrivateKeyFile
 	out.SSHAgentSocket = in.SSHAgentSocket
+	out.SSHHostPublicKey = in.SSHHostPublicKey
+	out.Labels = *(*map[string]string)(unsafe.Pointer(&in.Labels))
+	out.Taints = *(*[]v1.Taint)(unsafe.Pointer(&in.Taints))
+	out.KubeletExtraArgs = *(*map[string]string)(unsafe.Pointer(&in.KubeletExtraArgs))
+	out.Annotations = *(*map[string]string)(unsafe.Pointer(&in.Annotations))
+	out.PrivateNetworkGateway = in.PrivateNetworkGateway
 	out.Hostname = in.Hostname
 	out.OperatingSystem = in.OperatingSystem
 	out.IsLeader = in.IsLeader
@@ -378,6 +510,12 @@ func autoConvert_kubeone_HostConfig_To_v1alpha1_HostConfig(in *kubeone.HostConfi
 	out.SSHUsername = in.SSHUsername
 	out.SSHPrivateKeyFile = in.SSHPrivateKeyFile
 	out.SSHAgentSocket = in.SSHAgentSocket
+	out.SSHHostPublicKey = in.SSHHostPublicKey
+	out.Labels = *(*map[string]string)(unsafe.Pointer(&in.Labels))
+	out.Taints = *(*[]v1.Taint)(unsafe.Pointer(&in.Taints))
+	out.KubeletExtraArgs = *(*map[string]string)(unsafe.Pointer(&in.KubeletExtraArgs))
+	out.Annotations = *(*map[string]string)(unsafe.Pointer(&in.Annotations))
+	out.PrivateNetworkGateway = in.PrivateNetworkGateway
 	out.Hostname = in.Hostname
 	out.OperatingSystem = in.OperatingSystem
 	out.IsLeader = in.IsLeader
@@ -404,6 +542,9 @@ func autoConvert_v1alpha1_KubeOneCluster_To_kubeone_KubeOneCluster(in *KubeOneCl
 	if err := Convert_v1alpha1_ClusterNetworkConfig_To_kubeone_ClusterNetworkConfig(&in.ClusterNetwork, &out.ClusterNetwork, s); err != nil {
 		return err
 	}
+	if err := Convert_v1alpha1_ComponentConfig_To_kubeone_ComponentConfig(&in.ComponentConfig, &out.ComponentConfig, s); err != nil {
+		return err
+	}
 	if err := Convert_v1alpha1_ProxyConfig_To_kubeone_ProxyConfig(&in.Proxy, &out.Proxy, s); err != nil {
 		return err
 	}
@@ -436,6 +577,9 @@ func autoConvert_kubeone_KubeOneCluster_To_v1alpha1_KubeOneCluster(in *kubeone.K
 	if err := Convert_kubeone_ClusterNetworkConfig_To_v1alpha1_ClusterNetworkConfig(&in.ClusterNetwork, &out.ClusterNetwork, s); err != nil {
 		return err
 	}
+	if err := Convert_kubeone_ComponentConfig_To_v1alpha1_ComponentConfig(&in.ComponentConfig, &out.ComponentConfig, s); err != nil {
+		return err
+	}
 	if err := Convert_kubeone_ProxyConfig_To_v1alpha1_ProxyConfig(&in.Proxy, &out.Proxy, s); err != nil {
 		return err
 	}
@@ -456,6 +600,7 @@ func Convert_kubeone_KubeOneCluster_To_v1alpha1_KubeOneCluster(in *kubeone.KubeO
 func autoConvert_v1alpha1_MachineControllerConfig_To_kubeone_MachineControllerConfig(in *MachineControllerConfig, out *kubeone.MachineControllerConfig, s conversion.Scope) error {
 	out.Deploy = in.Deploy
 	out.Provider = kubeone.CloudProviderName(in.Provider)
+	out.Version = in.Version
 	return nil
 }
 
@@ -467,6 +612,7 @@ func Convert_v1alpha1_MachineControllerConfig_To_kubeone_MachineControllerConfig
 func autoConvert_kubeone_MachineControllerConfig_To_v1alpha1_MachineControllerConfig(in *kubeone.MachineControllerConfig, out *MachineControllerConfig, s conversion.Scope) error {
 	out.Deploy = in.Deploy
 	out.Provider = CloudProviderName(in.Provider)
+	out.Version = in.Version
 	return nil
 }
 
@@ -583,6 +729,7 @@ func autoConvert_v1alpha1_ProviderSpec_To_kubeone_ProviderSpec(in *ProviderSpec,
 	out.SSHPublicKeys = *(*[]string)(unsafe.Pointer(&in.SSHPublicKeys))
 	out.OperatingSystem = in.OperatingSystem
 	out.OperatingSystemSpec = *(*json.RawMessage)(unsafe.Pointer(&in.OperatingSystemSpec))
+	out.DiskEncryptionKeyID = in.DiskEncryptionKeyID
 	return nil
 }
 
@@ -597,6 +744,7 @@ func autoConvert_kubeone_ProviderSpec_To_v1alpha1_ProviderSpec(in *kubeone.Provi
 	out.SSHPublicKeys = *(*[]string)(unsafe.Pointer(&in.SSHPublicKeys))
 	out.OperatingSystem = in.OperatingSystem
 	out.OperatingSystemSpec = *(*json.RawMessage)(unsafe.Pointer(&in.OperatingSystemSpec))
+	out.DiskEncryptionKeyID = in.DiskEncryptionKeyID
 	return nil
 }
 
@@ -629,6 +777,28 @@ func Convert_kubeone_ProxyConfig_To_v1alpha1_ProxyConfig(in *kubeone.ProxyConfig
 	return autoConvert_kubeone_ProxyConfig_To_v1alpha1_ProxyConfig(in, out, s)
 }
 
+func autoConvert_v1alpha1_RollingUpdateConfig_To_kubeone_RollingUpdateConfig(in *RollingUpdateConfig, out *kubeone.RollingUpdateConfig, s conversion.Scope) error {
+	out.MaxSurge = (*intstr.IntOrString)(unsafe.Pointer(in.MaxSurge))
+	out.MaxUnavailable = (*intstr.IntOrString)(unsafe.Pointer(in.MaxUnavailable))
+	return nil
+}
+
+// Convert_v1alpha1_RollingUpdateConfig_To_kubeone_RollingUpdateConfig is an autogenerated conversion function.
+func Convert_v1alpha1_RollingUpdateConfig_To_kubeone_RollingUpdateConfig(in *RollingUpdateConfig, out *kubeone.RollingUpdateConfig, s conversion.Scope) error {
+	return autoConvert_v1alpha1_RollingUpdateConfig_To_kubeone_RollingUpdateConfig(in, out, s)
+}
+
+func autoConvert_kubeone_RollingUpdateConfig_To_v1alpha1_RollingUpdateConfig(in *kubeone.RollingUpdateConfig, out *RollingUpdateConfig, s conversion.Scope) error {
+	out.MaxSurge = (*intstr.IntOrString)(unsafe.Pointer(in.MaxSurge))
+	out.MaxUnavailable = (*intstr.IntOrString)(unsafe.Pointer(in.MaxUnavailable))
+	return nil
+}
+
+// Convert_kubeone_RollingUpdateConfig_To_v1alpha1_RollingUpdateConfig is an autogenerated conversion function.
+func Convert_kubeone_RollingUpdateConfig_To_v1alpha1_RollingUpdateConfig(in *kubeone.RollingUpdateConfig, out *RollingUpdateConfig, s conversion.Scope) error {
+	return autoConvert_kubeone_RollingUpdateConfig_To_v1alpha1_RollingUpdateConfig(in, out, s)
+}
+
 func autoConvert_v1alpha1_VersionConfig_To_kubeone_VersionConfig(in *VersionConfig, out *kubeone.VersionConfig, s conversion.Scope) error {
 	out.Kubernetes = in.Kubernetes
 	return nil
@@ -655,6 +825,7 @@ func autoConvert_v1alpha1_WorkerConfig_To_kubeone_WorkerConfig(in *WorkerConfig,
 	if err := Convert_v1alpha1_ProviderSpec_To_kubeone_ProviderSpec(&in.Config, &out.Config, s); err != nil {
 		return err
 	}
+	out.RollingUpdate = (*kubeone.RollingUpdateConfig)(unsafe.Pointer(in.RollingUpdate))
 	return nil
 }
 
@@ -669,6 +840,7 @@ func autoConvert_kubeone_WorkerConfig_To_v1alpha1_WorkerConfig(in *kubeone.Worke
 	if err := Convert_kubeone_ProviderSpec_To_v1alpha1_ProviderSpec(&in.Config, &out.Config, s); err != nil {
 		return err
 	}
+	out.RollingUpdate = (*RollingUpdateConfig)(unsafe.Pointer(in.RollingUpdate))
 	return nil
 }
 
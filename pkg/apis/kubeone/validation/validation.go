@@ -17,11 +17,16 @@ limitations under the License.
 package validation
 
 import (
+	"fmt"
 	"net"
+	"strings"
+	"time"
 
 	"github.com/Masterminds/semver"
 	"github.com/kubermatic/kubeone/pkg/apis/kubeone"
 
+	"k8s.io/apimachinery/pkg/util/intstr"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
@@ -43,13 +48,16 @@ func ValidateKubeOneCluster(c kubeone.KubeOneCluster) field.ErrorList {
 	if c.MachineController != nil && c.MachineController.Deploy {
 		allErrs = append(allErrs, ValidateMachineControllerConfig(c.MachineController, c.CloudProvider.Name, field.NewPath("machineController"))...)
 		allErrs = append(allErrs, ValidateWorkerConfig(c.Workers, field.NewPath("workers"))...)
+		allErrs = append(allErrs, ValidateWorkerDiskEncryption(c.Workers, c.CloudProvider.Name, field.NewPath("workers"))...)
 	} else if len(c.Workers) > 0 {
 		allErrs = append(allErrs, field.Invalid(field.NewPath("workers"), c.Workers, "machine-controller deployment is disabled, but configuration still contains worker definitions"))
 	}
 
+	allErrs = append(allErrs, ValidateAPIEndpoint(c.APIEndpoint, field.NewPath("apiEndpoint"))...)
 	allErrs = append(allErrs, ValidateVersionConfig(c.Versions, field.NewPath("versions"))...)
 	allErrs = append(allErrs, ValidateClusterNetworkConfig(c.ClusterNetwork, field.NewPath("clusterNetwork"))...)
 	allErrs = append(allErrs, ValidateFeatures(c.Features, field.NewPath("features"))...)
+	allErrs = append(allErrs, ValidateEtcdComponentConfig(c.ComponentConfig.Etcd, field.NewPath("componentConfig", "etcd"))...)
 
 	return allErrs
 }
@@ -81,6 +89,23 @@ func ValidateCloudProviderSpec(p kubeone.CloudProviderSpec, fldPath *field.Path)
 		allErrs = append(allErrs, field.Invalid(fldPath, p.Name, "unknown provider name"))
 	}
 
+	if p.CCMVersion != "" {
+		if _, err := semver.NewVersion(p.CCMVersion); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("ccmVersion"), p.CCMVersion, "ccm version must be a valid semantic version"))
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateAPIEndpoint validates the APIEndpoint structure
+func ValidateAPIEndpoint(a kubeone.APIEndpoint, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if a.InternalHost != "" && net.ParseIP(a.InternalHost) == nil && len(utilvalidation.IsDNS1123Subdomain(a.InternalHost)) > 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("internalHost"), a.InternalHost, "internal host must be an IP address or a DNS name"))
+	}
+
 	return allErrs
 }
 
@@ -102,6 +127,9 @@ func ValidateHostConfig(hosts []kubeone.HostConfig, fldPath *field.Path) field.E
 		if len(h.SSHUsername) == 0 {
 			allErrs = append(allErrs, field.Invalid(fldPath, h.SSHUsername, "no SSH username given"))
 		}
+		if h.PrivateNetworkGateway != "" && net.ParseIP(h.PrivateNetworkGateway) == nil {
+			allErrs = append(allErrs, field.Invalid(fldPath, h.PrivateNetworkGateway, "private network gateway must be an IP address"))
+		}
 	}
 
 	return allErrs
@@ -153,6 +181,44 @@ func ValidateWorkerConfig(workerset []kubeone.WorkerConfig, fldPath *field.Path)
 		if w.Replicas == nil || *w.Replicas < 1 {
 			allErrs = append(allErrs, field.Invalid(fldPath, w.Replicas, "replicas must be specified and >= 1"))
 		}
+		if w.RollingUpdate != nil {
+			// maxSurge defaults to 1 and maxUnavailable to 0, a rolling
+			// update can't make any progress if both are 0
+			maxSurgeZero := w.RollingUpdate.MaxSurge != nil && isZeroIntOrPercent(*w.RollingUpdate.MaxSurge)
+			maxUnavailableZero := w.RollingUpdate.MaxUnavailable == nil || isZeroIntOrPercent(*w.RollingUpdate.MaxUnavailable)
+			if maxSurgeZero && maxUnavailableZero {
+				allErrs = append(allErrs, field.Invalid(fldPath, w.RollingUpdate.MaxSurge, "maxSurge and maxUnavailable can't both be 0"))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// isZeroIntOrPercent reports whether the given value is 0 or 0%
+func isZeroIntOrPercent(v intstr.IntOrString) bool {
+	if v.Type == intstr.Int {
+		return v.IntVal == 0
+	}
+	return strings.TrimSuffix(v.StrVal, "%") == "0"
+}
+
+// diskEncryptionProviders are the cloud providers machine-controller can
+// encrypt the worker disks with a given key on
+var diskEncryptionProviders = map[kubeone.CloudProviderName]bool{
+	kubeone.CloudProviderNameAWS: true,
+	kubeone.CloudProviderNameGCE: true,
+}
+
+// ValidateWorkerDiskEncryption validates that disk encryption keys are only
+// given for cloud providers supporting them
+func ValidateWorkerDiskEncryption(workerset []kubeone.WorkerConfig, cloudProviderName kubeone.CloudProviderName, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for _, w := range workerset {
+		if w.Config.DiskEncryptionKeyID != "" && !diskEncryptionProviders[cloudProviderName] {
+			allErrs = append(allErrs, field.Invalid(fldPath, w.Config.DiskEncryptionKeyID, fmt.Sprintf("disk encryption keys are not supported for provider %q", cloudProviderName)))
+		}
 	}
 
 	return allErrs
@@ -199,6 +265,19 @@ func ValidateCNI(c *kubeone.CNI, fldPath *field.Path) field.ErrorList {
 	return allErrs
 }
 
+// ValidateEtcdComponentConfig validates the EtcdComponentConfig structure
+func ValidateEtcdComponentConfig(e kubeone.EtcdComponentConfig, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if e.CompactionInterval != "" {
+		if d, err := time.ParseDuration(e.CompactionInterval); err != nil || d <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("compactionInterval"), e.CompactionInterval, "compaction interval must be a positive duration, e.g. 5m"))
+		}
+	}
+
+	return allErrs
+}
+
 // ValidateFeatures validates the Features structure
 func ValidateFeatures(f kubeone.Features, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
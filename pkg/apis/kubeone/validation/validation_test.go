@@ -20,6 +20,8 @@ import (
 	"testing"
 
 	"github.com/kubermatic/kubeone/pkg/apis/kubeone"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 func TestValidateCloudProviderSpec(t *testing.T) {
@@ -74,6 +76,24 @@ func TestValidateCloudProviderSpec(t *testing.T) {
 			},
 			expectedError: true,
 		},
+		{
+			name: "valid provider config with pinned CCM version",
+			providerConfig: kubeone.CloudProviderSpec{
+				Name:       kubeone.CloudProviderNameHetzner,
+				External:   true,
+				CCMVersion: "v1.2.0",
+			},
+			expectedError: false,
+		},
+		{
+			name: "invalid provider config (non-semver CCM version)",
+			providerConfig: kubeone.CloudProviderSpec{
+				Name:       kubeone.CloudProviderNameHetzner,
+				External:   true,
+				CCMVersion: "latest",
+			},
+			expectedError: true,
+		},
 	}
 
 	for _, tc := range tests {
@@ -87,6 +107,50 @@ func TestValidateCloudProviderSpec(t *testing.T) {
 	}
 }
 
+func TestValidateAPIEndpoint(t *testing.T) {
+	tests := []struct {
+		name          string
+		apiEndpoint   kubeone.APIEndpoint
+		expectedError bool
+	}{
+		{
+			name:          "valid api endpoint (no internal host)",
+			apiEndpoint:   kubeone.APIEndpoint{Host: "lb.example.com", Port: 6443},
+			expectedError: false,
+		},
+		{
+			name:          "valid api endpoint (internal ip address)",
+			apiEndpoint:   kubeone.APIEndpoint{Host: "lb.example.com", Port: 6443, InternalHost: "10.0.0.100"},
+			expectedError: false,
+		},
+		{
+			name:          "valid api endpoint (internal dns name)",
+			apiEndpoint:   kubeone.APIEndpoint{Host: "lb.example.com", Port: 6443, InternalHost: "lb.internal.example.com"},
+			expectedError: false,
+		},
+		{
+			name:          "invalid api endpoint (internal host with port)",
+			apiEndpoint:   kubeone.APIEndpoint{Host: "lb.example.com", Port: 6443, InternalHost: "10.0.0.100:6443"},
+			expectedError: true,
+		},
+		{
+			name:          "invalid api endpoint (internal host with scheme)",
+			apiEndpoint:   kubeone.APIEndpoint{Host: "lb.example.com", Port: 6443, InternalHost: "https://lb.internal.example.com"},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			errs := ValidateAPIEndpoint(tc.apiEndpoint, nil)
+			if (len(errs) == 0) == tc.expectedError {
+				t.Errorf("test case failed: expected %v, but got %v", tc.expectedError, (len(errs) != 0))
+			}
+		})
+	}
+}
+
 func TestValidateHostConfig(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -191,6 +255,32 @@ func TestValidateHostConfig(t *testing.T) {
 			},
 			expectedError: true,
 		},
+		{
+			name: "valid host config (with private network gateway)",
+			hostConfig: []kubeone.HostConfig{
+				{
+					PublicAddress:         "192.168.1.1",
+					PrivateAddress:        "192.168.0.1",
+					SSHPrivateKeyFile:     "test",
+					SSHUsername:           "root",
+					PrivateNetworkGateway: "192.168.0.254",
+				},
+			},
+			expectedError: false,
+		},
+		{
+			name: "invalid host config (invalid private network gateway)",
+			hostConfig: []kubeone.HostConfig{
+				{
+					PublicAddress:         "192.168.1.1",
+					PrivateAddress:        "192.168.0.1",
+					SSHPrivateKeyFile:     "test",
+					SSHUsername:           "root",
+					PrivateNetworkGateway: "gateway.local",
+				},
+			},
+			expectedError: true,
+		},
 	}
 
 	for _, tc := range tests {
@@ -388,6 +478,47 @@ func TestValidateWorkerConfig(t *testing.T) {
 			},
 			expectedError: true,
 		},
+		{
+			name: "valid worker config (maxSurge 0 and maxUnavailable 1)",
+			workerConfig: []kubeone.WorkerConfig{
+				{
+					Name:     "test-1",
+					Replicas: intPtr(3),
+					RollingUpdate: &kubeone.RollingUpdateConfig{
+						MaxSurge:       intstrPtr(intstr.FromInt(0)),
+						MaxUnavailable: intstrPtr(intstr.FromInt(1)),
+					},
+				},
+			},
+			expectedError: false,
+		},
+		{
+			name: "invalid worker config (maxSurge and maxUnavailable 0)",
+			workerConfig: []kubeone.WorkerConfig{
+				{
+					Name:     "test-1",
+					Replicas: intPtr(3),
+					RollingUpdate: &kubeone.RollingUpdateConfig{
+						MaxSurge:       intstrPtr(intstr.FromInt(0)),
+						MaxUnavailable: intstrPtr(intstr.FromString("0%")),
+					},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid worker config (maxSurge 0 and default maxUnavailable)",
+			workerConfig: []kubeone.WorkerConfig{
+				{
+					Name:     "test-1",
+					Replicas: intPtr(3),
+					RollingUpdate: &kubeone.RollingUpdateConfig{
+						MaxSurge: intstrPtr(intstr.FromString("0%")),
+					},
+				},
+			},
+			expectedError: true,
+		},
 	}
 
 	for _, tc := range tests {
@@ -401,6 +532,58 @@ func TestValidateWorkerConfig(t *testing.T) {
 	}
 }
 
+func TestValidateWorkerDiskEncryption(t *testing.T) {
+	tests := []struct {
+		name          string
+		workerConfig  []kubeone.WorkerConfig
+		provider      kubeone.CloudProviderName
+		expectedError bool
+	}{
+		{
+			name: "valid worker config (no disk encryption key)",
+			workerConfig: []kubeone.WorkerConfig{
+				{Name: "test-1"},
+			},
+			provider:      kubeone.CloudProviderNameHetzner,
+			expectedError: false,
+		},
+		{
+			name: "valid worker config (disk encryption key on aws)",
+			workerConfig: []kubeone.WorkerConfig{
+				{Name: "test-1", Config: kubeone.ProviderSpec{DiskEncryptionKeyID: "arn:aws:kms:eu-central-1:123:key/abc"}},
+			},
+			provider:      kubeone.CloudProviderNameAWS,
+			expectedError: false,
+		},
+		{
+			name: "valid worker config (disk encryption key on gce)",
+			workerConfig: []kubeone.WorkerConfig{
+				{Name: "test-1", Config: kubeone.ProviderSpec{DiskEncryptionKeyID: "projects/p/locations/l/keyRings/r/cryptoKeys/k"}},
+			},
+			provider:      kubeone.CloudProviderNameGCE,
+			expectedError: false,
+		},
+		{
+			name: "invalid worker config (disk encryption key on unsupported provider)",
+			workerConfig: []kubeone.WorkerConfig{
+				{Name: "test-1", Config: kubeone.ProviderSpec{DiskEncryptionKeyID: "key"}},
+			},
+			provider:      kubeone.CloudProviderNameHetzner,
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			errs := ValidateWorkerDiskEncryption(tc.workerConfig, tc.provider, nil)
+			if (len(errs) == 0) == tc.expectedError {
+				t.Errorf("test case failed: expected %v, but got %v", tc.expectedError, (len(errs) != 0))
+			}
+		})
+	}
+}
+
 func TestValidateClusterNetworkConfig(t *testing.T) {
 	tests := []struct {
 		name                 string
@@ -449,6 +632,52 @@ func TestValidateClusterNetworkConfig(t *testing.T) {
 	}
 }
 
+func TestValidateEtcdComponentConfig(t *testing.T) {
+	tests := []struct {
+		name          string
+		etcdConfig    kubeone.EtcdComponentConfig
+		expectedError bool
+	}{
+		{
+			name:          "valid etcd config (empty config)",
+			etcdConfig:    kubeone.EtcdComponentConfig{},
+			expectedError: false,
+		},
+		{
+			name: "valid etcd config",
+			etcdConfig: kubeone.EtcdComponentConfig{
+				DataDir:            "/mnt/etcd",
+				CompactionInterval: "5m",
+			},
+			expectedError: false,
+		},
+		{
+			name: "invalid etcd config (invalid compaction interval)",
+			etcdConfig: kubeone.EtcdComponentConfig{
+				CompactionInterval: "5",
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid etcd config (negative compaction interval)",
+			etcdConfig: kubeone.EtcdComponentConfig{
+				CompactionInterval: "-5m",
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			errs := ValidateEtcdComponentConfig(tc.etcdConfig, nil)
+			if (len(errs) == 0) == tc.expectedError {
+				t.Errorf("test case failed: expected %v, but got %v", tc.expectedError, (len(errs) != 0))
+			}
+		})
+	}
+}
+
 func TestValidateFeatures(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -562,3 +791,7 @@ func TestValidateOIDCConfig(t *testing.T) {
 func intPtr(i int) *int {
 	return &i
 }
+
+func intstrPtr(v intstr.IntOrString) *intstr.IntOrString {
+	return &v
+}
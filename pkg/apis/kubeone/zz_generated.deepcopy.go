@@ -23,12 +23,19 @@ package kubeone
 import (
 	json "encoding/json"
 
+	v1 "k8s.io/api/core/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	intstr "k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *APIEndpoint) DeepCopyInto(out *APIEndpoint) {
 	*out = *in
+	if in.AlternativeNames != nil {
+		in, out := &in.AlternativeNames, &out.AlternativeNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -95,6 +102,48 @@ func (in *ClusterNetworkConfig) DeepCopy() *ClusterNetworkConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentConfig) DeepCopyInto(out *ComponentConfig) {
+	*out = *in
+	in.APIServer.DeepCopyInto(&out.APIServer)
+	in.ControllerManager.DeepCopyInto(&out.ControllerManager)
+	out.Etcd = in.Etcd
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentConfig.
+func (in *ComponentConfig) DeepCopy() *ComponentConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControlPlaneComponentConfig) DeepCopyInto(out *ControlPlaneComponentConfig) {
+	*out = *in
+	if in.ExtraArgs != nil {
+		in, out := &in.ExtraArgs, &out.ExtraArgs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControlPlaneComponentConfig.
+func (in *ControlPlaneComponentConfig) DeepCopy() *ControlPlaneComponentConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ControlPlaneComponentConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DynamicAuditLog) DeepCopyInto(out *DynamicAuditLog) {
 	*out = *in
@@ -111,6 +160,22 @@ func (in *DynamicAuditLog) DeepCopy() *DynamicAuditLog {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdComponentConfig) DeepCopyInto(out *EtcdComponentConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdComponentConfig.
+func (in *EtcdComponentConfig) DeepCopy() *EtcdComponentConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdComponentConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Features) DeepCopyInto(out *Features) {
 	*out = *in
@@ -150,6 +215,34 @@ func (in *Features) DeepCopy() *Features {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HostConfig) DeepCopyInto(out *HostConfig) {
 	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Taints != nil {
+		in, out := &in.Taints, &out.Taints
+		*out = make([]v1.Taint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.KubeletExtraArgs != nil {
+		in, out := &in.KubeletExtraArgs, &out.KubeletExtraArgs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -170,12 +263,15 @@ func (in *KubeOneCluster) DeepCopyInto(out *KubeOneCluster) {
 	if in.Hosts != nil {
 		in, out := &in.Hosts, &out.Hosts
 		*out = make([]HostConfig, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	out.APIEndpoint = in.APIEndpoint
+	in.APIEndpoint.DeepCopyInto(&out.APIEndpoint)
 	out.CloudProvider = in.CloudProvider
 	out.Versions = in.Versions
 	in.ClusterNetwork.DeepCopyInto(&out.ClusterNetwork)
+	in.ComponentConfig.DeepCopyInto(&out.ComponentConfig)
 	out.Proxy = in.Proxy
 	if in.Workers != nil {
 		in, out := &in.Workers, &out.Workers
@@ -353,6 +449,32 @@ func (in *ProxyConfig) DeepCopy() *ProxyConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RollingUpdateConfig) DeepCopyInto(out *RollingUpdateConfig) {
+	*out = *in
+	if in.MaxSurge != nil {
+		in, out := &in.MaxSurge, &out.MaxSurge
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollingUpdateConfig.
+func (in *RollingUpdateConfig) DeepCopy() *RollingUpdateConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RollingUpdateConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VersionConfig) DeepCopyInto(out *VersionConfig) {
 	*out = *in
@@ -378,6 +500,11 @@ func (in *WorkerConfig) DeepCopyInto(out *WorkerConfig) {
 		**out = **in
 	}
 	in.Config.DeepCopyInto(&out.Config)
+	if in.RollingUpdate != nil {
+		in, out := &in.RollingUpdate, &out.RollingUpdate
+		*out = new(RollingUpdateConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -440,6 +440,23 @@ cloudProvider:
   external: {{ .CloudProviderExternal }}
   # Path to file that will be uploaded and used as custom '--cloud-config' file.
   cloudConfig: "{{ .CloudProviderCloudCfg }}"
+  # Pins the external CCM version (defaults to the version bundled with KubeOne)
+  # ccmVersion: ""
+
+# Extra flags passed to the control plane components. They take precedence
+# over the flags set by KubeOne.
+# componentConfig:
+#   apiServer:
+#     extraArgs:
+#       feature-gates: 'TTLAfterFinished=true'
+#   controllerManager:
+#     extraArgs:
+#       feature-gates: 'TTLAfterFinished=true'
+#   # Directory etcd stores its data in, e.g. a dedicated volume
+#   etcd:
+#     dataDir: '/var/lib/etcd'
+#     # Interval in which etcd compacts its history, disabled by default
+#     compactionInterval: '5m'
 
 features:
   # Enables PodSecurityPolicy admission plugin in API server, as well as creates
@@ -513,6 +530,22 @@ features:
 #   # prefixed with "env:" to refer to an environment variable.
 #   sshPrivateKeyFile: '/home/me/.ssh/id_rsa'
 #   sshAgentSocket: 'env:SSH_AUTH_SOCK'
+#   # If set, KubeOne refuses to connect to the host unless it presents
+#   # this public key (in the authorized_keys format).
+#   sshHostPublicKey: 'ssh-ed25519 AAAA...'
+#   # Labels and taints applied to the Node object of the host. If any
+#   # taints are given, they replace the default control plane taint.
+#   labels:
+#     mylabel: 'cp-1'
+#   taints:
+#   - key: 'node-role.kubernetes.io/master'
+#     effect: 'NoSchedule'
+#   # Extra flags passed to the kubelet of the host
+#   kubeletExtraArgs:
+#     max-pods: '50'
+#   # Gateway for the private network traffic. Informational only, KubeOne
+#   # doesn't configure any routes using it yet
+#   privateNetworkGateway: '172.18.0.254'
 
 # The API server can also be overwritten by Terraform. Provide the
 # external address of your load balancer or the public addresses of
@@ -520,6 +553,12 @@ features:
 # apiEndpoint:
 #   host: '{{ .APIEndpointHost }}'
 #   port: {{ .APIEndpointPort }}
+#   # additional names to include in the API server certificate
+#   alternativeNames:
+#   - 'api.example.com'
+#   # internal load balancer used by the nodes when the API is not
+#   # reachable on the host above from within the cluster
+#   # internalHost: 'api.internal.example.com'
 
 # If the cluster runs on bare metal or an unsupported cloud provider,
 # you can disable the machine-controller deployment entirely. In this
@@ -528,6 +567,8 @@ machineController:
   deploy: {{ .DeployMachineController }}
   # Defines for what provider the machine-controller will be configured (defaults to cloudProvider.Name)
   # provider: ""
+  # Pins the machine-controller version (defaults to the version bundled with KubeOne)
+  # version: ""
 
 # Proxy is used to configure HTTP_PROXY, HTTPS_PROXY and NO_PROXY
 # for Docker daemon and kubelet, and to be used when provisioning cluster
@@ -543,6 +584,11 @@ machineController:
 # workers:
 # - name: fra1-a
 #   replicas: 1
+#   # Machines replaced at once during updates (defaults to maxSurge 1,
+#   # maxUnavailable 0)
+#   rollingUpdate:
+#     maxSurge: 1
+#     maxUnavailable: 0
 #   providerSpec:
 #     labels:
 #       mylabel: 'fra1-a'
@@ -568,6 +614,10 @@ machineController:
 #     operatingSystem: 'ubuntu'
 #     operatingSystemSpec:
 #       distUpgradeOnBoot: true
+#     # key used to encrypt the disks of the machines, overrides
+#     # diskKMSKeyID on AWS and diskEncryptionKey.kmsKeyName on GCE, other
+#     # providers are not supported yet
+#     # diskEncryptionKeyID: 'arn:aws:kms:eu-central-1:123456789012:key/abcd'
 # - name: fra1-b
 #   replicas: 1
 #   providerSpec:
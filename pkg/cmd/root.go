@@ -22,10 +22,7 @@ import (
 
 	"github.com/spf13/cobra"
 
-	apiextensionsscheme "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/scheme"
-	"k8s.io/client-go/kubernetes/scheme"
-	apiregscheme "k8s.io/kube-aggregator/pkg/client/clientset_generated/clientset/scheme"
-	clusterscheme "sigs.k8s.io/cluster-api/pkg/client/clientset_generated/clientset/scheme"
+	"github.com/kubermatic/kubeone/pkg/templates"
 )
 
 // rootCmd is the KubeOne base command
@@ -34,15 +31,7 @@ import (
 func Execute() {
 	// quite unlikely to happen errors here, but in case if errors present:
 	// let's panic
-	if err := clusterscheme.AddToScheme(scheme.Scheme); err != nil {
-		panic(err)
-	}
-
-	if err := apiextensionsscheme.AddToScheme(scheme.Scheme); err != nil {
-		panic(err)
-	}
-
-	if err := apiregscheme.AddToScheme(scheme.Scheme); err != nil {
+	if err := templates.RegisterSchemes(); err != nil {
 		panic(err)
 	}
 
@@ -53,6 +53,7 @@ type Opts struct {
 	PrivateKey  string
 	KeyFile     string
 	AgentSocket string
+	HostKey     string
 	Timeout     time.Duration
 }
 
@@ -104,6 +105,16 @@ func NewConnection(o Opts) (Connection, error) {
 		return nil, errors.Wrap(err, "failed to validate ssh connection options")
 	}
 
+	hostKeyCallback := ssh.InsecureIgnoreHostKey()
+	if len(o.HostKey) > 0 {
+		hostKey, _, _, _, parseErr := ssh.ParseAuthorizedKey([]byte(o.HostKey))
+		if parseErr != nil {
+			return nil, errors.Wrap(parseErr, "the given SSH host key could not be parsed")
+		}
+
+		hostKeyCallback = ssh.FixedHostKey(hostKey)
+	}
+
 	authMethods := make([]ssh.AuthMethod, 0)
 
 	if len(o.Password) > 0 {
@@ -150,7 +161,7 @@ func NewConnection(o Opts) (Connection, error) {
 		User:            o.Username,
 		Timeout:         o.Timeout,
 		Auth:            authMethods,
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		HostKeyCallback: hostKeyCallback,
 	}
 
 	// do not use fmt.Sprintf() to allow proper IPv6 handling if hostname is an IP address
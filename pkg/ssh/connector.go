@@ -50,6 +50,7 @@ func (c *Connector) Connect(node kubeoneapi.HostConfig) (Connection, error) {
 			Hostname:    node.PublicAddress,
 			KeyFile:     node.SSHPrivateKeyFile,
 			AgentSocket: node.SSHAgentSocket,
+			HostKey:     node.SSHHostPublicKey,
 			Timeout:     10 * time.Second,
 		}
 
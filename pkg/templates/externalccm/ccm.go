@@ -53,6 +53,24 @@ func Ensure(ctx *util.Context) error {
 	}
 }
 
+// ccmVersion returns the CCM version to deploy along with the constraint the
+// already deployed version has to satisfy in order to be updated. A pinned
+// version is always deployed, so no constraint is returned for it.
+func ccmVersion(cluster *kubeoneapi.KubeOneCluster, defaultVersion string) (string, *semver.Constraints, error) {
+	if pinned := cluster.CloudProvider.CCMVersion; pinned != "" {
+		if _, err := semver.NewVersion(pinned); err != nil {
+			return "", nil, errors.Wrapf(err, "failed to parse pinned CCM version %q", pinned)
+		}
+		return pinned, nil, nil
+	}
+
+	want, err := semver.NewConstraint("<= " + defaultVersion)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to parse CCM version constraint")
+	}
+	return defaultVersion, want, nil
+}
+
 func simpleCreateOrUpdate(ctx context.Context, client dynclient.Client, obj runtime.Object) error {
 	okFunc := func(runtime.Object) error { return nil }
 	_, err := controllerutil.CreateOrUpdate(ctx, client, obj, okFunc)
@@ -71,6 +89,11 @@ func mutateDeploymentWithVersionCheck(want *semver.Constraints) func(obj runtime
 			return nil
 		}
 
+		if want == nil {
+			// pinned version, always update
+			return nil
+		}
+
 		if len(dep.Spec.Template.Spec.Containers) != 1 {
 			return errors.New("unable to choose a CCM container, as number of containers > 1")
 		}
@@ -0,0 +1,81 @@
+/*
+Copyright 2019 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package externalccm
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver"
+
+	kubeoneapi "github.com/kubermatic/kubeone/pkg/apis/kubeone"
+)
+
+func TestCCMVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		pinned          string
+		expectedVersion string
+		expectedUpdate  map[string]bool
+		expectedError   bool
+	}{
+		{
+			name:            "default version",
+			expectedVersion: "v1.3.0",
+			expectedUpdate:  map[string]bool{"v1.2.0": true, "v1.3.0": true, "v1.4.0": false},
+		},
+		{
+			name:            "pinned lower than default",
+			pinned:          "v1.2.0",
+			expectedVersion: "v1.2.0",
+			expectedUpdate:  map[string]bool{"v1.2.0": true, "v1.3.0": true, "v1.4.0": true},
+		},
+		{
+			name:          "pinned non-semver",
+			pinned:        "latest",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			cluster := &kubeoneapi.KubeOneCluster{
+				CloudProvider: kubeoneapi.CloudProviderSpec{CCMVersion: tc.pinned},
+			}
+			version, want, err := ccmVersion(cluster, "v1.3.0")
+			if (err != nil) != tc.expectedError {
+				t.Fatalf("expected error = %v, but got %v", tc.expectedError, err)
+			}
+			if tc.expectedError {
+				return
+			}
+			if version != tc.expectedVersion {
+				t.Fatalf("expected version %q, but got %q", tc.expectedVersion, version)
+			}
+			for deployed, update := range tc.expectedUpdate {
+				v := semver.MustParse(deployed)
+				if got := want == nil || want.Check(v); got != update {
+					t.Errorf("deployed %s: expected update = %v, but got %v", deployed, update, got)
+				}
+			}
+		})
+	}
+}
@@ -19,7 +19,6 @@ package externalccm
 import (
 	"context"
 
-	"github.com/Masterminds/semver"
 	"github.com/pkg/errors"
 
 	"github.com/kubermatic/kubeone/pkg/util"
@@ -61,11 +60,11 @@ func ensureDigitalOcean(ctx *util.Context) error {
 		return errors.Wrap(err, "failed to ensure digitalocean CCM ClusterRoleBinding")
 	}
 
-	dep := doDeployment()
-	want, err := semver.NewConstraint("<= " + digitaloceanCCMVersion)
+	version, want, err := ccmVersion(ctx.Cluster, digitaloceanCCMVersion)
 	if err != nil {
-		return errors.Wrap(err, "failed to parse digitalocean CCM version constraint")
+		return errors.Wrap(err, "failed to determine digitalocean CCM version")
 	}
+	dep := doDeployment(version)
 
 	_, err = controllerutil.CreateOrUpdate(bgctx,
 		ctx.DynamicClient,
@@ -171,7 +170,7 @@ func doClusterRoleBinding() *rbacv1.ClusterRoleBinding {
 	}
 }
 
-func doDeployment() *appsv1.Deployment {
+func doDeployment(version string) *appsv1.Deployment {
 	var (
 		replicas  int32 = 1
 		revisions int32 = 2
@@ -227,7 +226,7 @@ func doDeployment() *appsv1.Deployment {
 					Containers: []corev1.Container{
 						{
 							Name:  "digitalocean-cloud-controller-manager",
-							Image: "digitalocean/digitalocean-cloud-controller-manager:" + digitaloceanCCMVersion,
+							Image: "digitalocean/digitalocean-cloud-controller-manager:" + version,
 							Command: []string{
 								"/bin/digitalocean-cloud-controller-manager",
 								"--cloud-provider=digitalocean",
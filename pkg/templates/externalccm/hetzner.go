@@ -19,7 +19,6 @@ package externalccm
 import (
 	"context"
 
-	"github.com/Masterminds/semver"
 	"github.com/pkg/errors"
 
 	"github.com/kubermatic/kubeone/pkg/util"
@@ -56,11 +55,11 @@ func ensureHetzner(ctx *util.Context) error {
 		return errors.Wrap(err, "failed to ensure hetzner CCM ClusterRoleBinding")
 	}
 
-	dep := hetznerDeployment()
-	want, err := semver.NewConstraint("<= " + hetznerCCMVersion)
+	version, want, err := ccmVersion(ctx.Cluster, hetznerCCMVersion)
 	if err != nil {
-		return errors.Wrap(err, "failed to parse hetzner CCM version constraint")
+		return errors.Wrap(err, "failed to determine hetzner CCM version")
 	}
+	dep := hetznerDeployment(version)
 
 	_, err = controllerutil.CreateOrUpdate(bgctx,
 		ctx.DynamicClient,
@@ -110,7 +109,7 @@ func hetznerClusterRoleBinding() *rbacv1.ClusterRoleBinding {
 	}
 }
 
-func hetznerDeployment() *appsv1.Deployment {
+func hetznerDeployment(version string) *appsv1.Deployment {
 	var (
 		replicas  int32 = 1
 		revisions int32 = 2
@@ -166,7 +165,7 @@ func hetznerDeployment() *appsv1.Deployment {
 					Containers: []corev1.Container{
 						{
 							Name:  "hcloud-cloud-controller-manager",
-							Image: "hetznercloud/hcloud-cloud-controller-manager:" + hetznerCCMVersion,
+							Image: "hetznercloud/hcloud-cloud-controller-manager:" + version,
 							Command: []string{
 								"/bin/hcloud-cloud-controller-manager",
 								"--cloud-provider=hcloud",
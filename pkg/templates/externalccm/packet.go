@@ -19,7 +19,6 @@ package externalccm
 import (
 	"context"
 
-	"github.com/Masterminds/semver"
 	"github.com/pkg/errors"
 
 	"github.com/kubermatic/kubeone/pkg/util"
@@ -61,11 +60,11 @@ func ensurePacket(ctx *util.Context) error {
 		return errors.Wrap(err, "failed to ensure packet CCM ClusterRoleBinding")
 	}
 
-	dep := packetDeployment()
-	want, err := semver.NewConstraint("<= " + packetCCMVersion)
+	version, want, err := ccmVersion(ctx.Cluster, packetCCMVersion)
 	if err != nil {
-		return errors.Wrap(err, "failed to parse packet CCM version constraint")
+		return errors.Wrap(err, "failed to determine packet CCM version")
 	}
+	dep := packetDeployment(version)
 
 	_, err = controllerutil.CreateOrUpdate(bgctx,
 		ctx.DynamicClient,
@@ -172,7 +171,7 @@ func packetClusterRoleBinding() *rbacv1.ClusterRoleBinding {
 	}
 }
 
-func packetDeployment() *appsv1.Deployment {
+func packetDeployment(version string) *appsv1.Deployment {
 	var (
 		replicas int32 = 1
 	)
@@ -226,7 +225,7 @@ func packetDeployment() *appsv1.Deployment {
 					Containers: []corev1.Container{
 						{
 							Name:  "packet-cloud-controller-manager",
-							Image: "packethost/packet-ccm:" + packetCCMVersion,
+							Image: "packethost/packet-ccm:" + version,
 							Command: []string{
 								"./packet-cloud-controller-manager",
 								"--cloud-provider=packet",
@@ -18,11 +18,13 @@ package v1beta1
 
 import (
 	"fmt"
+	"path"
 	"strings"
 
 	kubeadmv1beta1 "github.com/kubermatic/kubeone/pkg/apis/kubeadm/v1beta1"
 	kubeoneapi "github.com/kubermatic/kubeone/pkg/apis/kubeone"
 	"github.com/kubermatic/kubeone/pkg/features"
+	"github.com/kubermatic/kubeone/pkg/templates"
 	"github.com/kubermatic/kubeone/pkg/util"
 
 	corev1 "k8s.io/api/core/v1"
@@ -50,6 +52,22 @@ func NewConfig(ctx *util.Context, host kubeoneapi.HostConfig) ([]runtime.Object,
 		},
 	}
 
+	if len(host.Labels) > 0 {
+		labels := make([]string, 0, len(host.Labels))
+		for _, k := range templates.SortedStringMapKeys(host.Labels) {
+			labels = append(labels, fmt.Sprintf("%s=%s", k, host.Labels[k]))
+		}
+		nodeRegistration.KubeletExtraArgs["node-labels"] = strings.Join(labels, ",")
+	}
+
+	if len(host.Taints) > 0 {
+		nodeRegistration.Taints = host.Taints
+	}
+
+	for k, v := range host.KubeletExtraArgs {
+		nodeRegistration.KubeletExtraArgs[k] = v
+	}
+
 	if ctx.JoinToken == "" {
 		tokenStr, err := bootstraputil.GenerateBootstrapToken()
 		if err != nil {
@@ -63,7 +81,12 @@ func NewConfig(ctx *util.Context, host kubeoneapi.HostConfig) ([]runtime.Object,
 		return nil, err
 	}
 
-	controlPlaneEndpoint := fmt.Sprintf("%s:%d", cluster.APIEndpoint.Host, cluster.APIEndpoint.Port)
+	// the nodes and in-cluster components use the internal endpoint, if any
+	endpointHost := cluster.APIEndpoint.Host
+	if cluster.APIEndpoint.InternalHost != "" {
+		endpointHost = cluster.APIEndpoint.InternalHost
+	}
+	controlPlaneEndpoint := fmt.Sprintf("%s:%d", endpointHost, cluster.APIEndpoint.Port)
 
 	initConfig := &kubeadmv1beta1.InitConfiguration{
 		TypeMeta: metav1.TypeMeta{
@@ -115,7 +138,7 @@ func NewConfig(ctx *util.Context, host kubeoneapi.HostConfig) ([]runtime.Object,
 				},
 				ExtraVolumes: []kubeadmv1beta1.HostPathMount{},
 			},
-			CertSANs: []string{strings.ToLower(cluster.APIEndpoint.Host)},
+			CertSANs: certSANs(cluster.APIEndpoint),
 		},
 		ControllerManager: kubeadmv1beta1.ControlPlaneComponent{
 			ExtraArgs:    map[string]string{},
@@ -165,8 +188,79 @@ func NewConfig(ctx *util.Context, host kubeoneapi.HostConfig) ([]runtime.Object,
 
 	features.UpdateKubeadmClusterConfiguration(cluster.Features, clusterConfig)
 
+	for k, v := range cluster.ComponentConfig.APIServer.ExtraArgs {
+		clusterConfig.APIServer.ExtraArgs[k] = v
+	}
+	for k, v := range cluster.ComponentConfig.ControllerManager.ExtraArgs {
+		clusterConfig.ControllerManager.ExtraArgs[k] = v
+	}
+
+	// the audit policy and log files, custom certificates and the OIDC CA
+	// live on the host, so they have to be mounted into the API server pod
+	hostFiles := []struct{ name, flag string }{
+		{name: "audit-policy", flag: "audit-policy-file"},
+		{name: "tls-cert", flag: "tls-cert-file"},
+		{name: "tls-private-key", flag: "tls-private-key-file"},
+		{name: "oidc-ca", flag: "oidc-ca-file"},
+	}
+	for _, f := range hostFiles {
+		file := clusterConfig.APIServer.ExtraArgs[f.flag]
+		// the kubeadm managed certificates are mounted already
+		if file == "" || strings.HasPrefix(file, "/etc/kubernetes/pki/") {
+			continue
+		}
+		clusterConfig.APIServer.ExtraVolumes = append(clusterConfig.APIServer.ExtraVolumes, kubeadmv1beta1.HostPathMount{
+			Name:      f.name,
+			HostPath:  file,
+			MountPath: file,
+			ReadOnly:  true,
+			PathType:  corev1.HostPathFile,
+		})
+	}
+	if logPath := clusterConfig.APIServer.ExtraArgs["audit-log-path"]; logPath != "" && logPath != "-" {
+		logDir := path.Dir(logPath)
+		clusterConfig.APIServer.ExtraVolumes = append(clusterConfig.APIServer.ExtraVolumes, kubeadmv1beta1.HostPathMount{
+			Name:      "audit-log",
+			HostPath:  logDir,
+			MountPath: logDir,
+			PathType:  corev1.HostPathDirectoryOrCreate,
+		})
+	}
+
+	if etcd := cluster.ComponentConfig.Etcd; etcd.DataDir != "" || etcd.CompactionInterval != "" {
+		clusterConfig.Etcd.Local = &kubeadmv1beta1.LocalEtcd{
+			DataDir: etcd.DataDir,
+		}
+		if etcd.CompactionInterval != "" {
+			clusterConfig.Etcd.Local.ExtraArgs = map[string]string{
+				"auto-compaction-mode":      "periodic",
+				"auto-compaction-retention": etcd.CompactionInterval,
+			}
+		}
+	}
+
 	initConfig.NodeRegistration = nodeRegistration
 	joinConfig.NodeRegistration = nodeRegistration
 
 	return []runtime.Object{initConfig, joinConfig, clusterConfig}, nil
 }
+
+// certSANs returns the API server certificate SANs, i.e. the API endpoint
+// host followed by the internal host and the alternative names
+func certSANs(endpoint kubeoneapi.APIEndpoint) []string {
+	sans := []string{strings.ToLower(endpoint.Host)}
+	seen := map[string]bool{sans[0]: true}
+	names := endpoint.AlternativeNames
+	if endpoint.InternalHost != "" {
+		names = append([]string{endpoint.InternalHost}, names...)
+	}
+	for _, name := range names {
+		name = strings.ToLower(name)
+		if !seen[name] {
+			seen[name] = true
+			sans = append(sans, name)
+		}
+	}
+
+	return sans
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2019 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"reflect"
+	"testing"
+
+	kubeadmv1beta1 "github.com/kubermatic/kubeone/pkg/apis/kubeadm/v1beta1"
+	kubeoneapi "github.com/kubermatic/kubeone/pkg/apis/kubeone"
+	"github.com/kubermatic/kubeone/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// testConfigs returns the kubeadm configs for the given cluster
+func testConfigs(t *testing.T, cluster *kubeoneapi.KubeOneCluster) (*kubeadmv1beta1.JoinConfiguration, *kubeadmv1beta1.ClusterConfiguration) {
+	t.Helper()
+
+	host := kubeoneapi.HostConfig{PublicAddress: "192.0.2.1", PrivateAddress: "10.0.0.1", Hostname: "cp-0"}
+	objs, err := NewConfig(&util.Context{Cluster: cluster}, host)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return objs[1].(*kubeadmv1beta1.JoinConfiguration), objs[2].(*kubeadmv1beta1.ClusterConfiguration)
+}
+
+func TestNewConfigAPIEndpoint(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name             string
+		endpoint         kubeoneapi.APIEndpoint
+		expectedEndpoint string
+		expectedSANs     []string
+	}{
+		{
+			name:             "external endpoint",
+			endpoint:         kubeoneapi.APIEndpoint{Host: "LB.example.com", Port: 6443, AlternativeNames: []string{"api.example.com", "lb.example.com"}},
+			expectedEndpoint: "LB.example.com:6443",
+			expectedSANs:     []string{"lb.example.com", "api.example.com"},
+		},
+		{
+			name:             "internal endpoint",
+			endpoint:         kubeoneapi.APIEndpoint{Host: "lb.example.com", Port: 6443, InternalHost: "10.0.0.100", AlternativeNames: []string{"10.0.0.100", "api.example.com"}},
+			expectedEndpoint: "10.0.0.100:6443",
+			expectedSANs:     []string{"lb.example.com", "10.0.0.100", "api.example.com"},
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			joinConfig, clusterConfig := testConfigs(t, &kubeoneapi.KubeOneCluster{Name: "test", APIEndpoint: tc.endpoint})
+
+			if clusterConfig.ControlPlaneEndpoint != tc.expectedEndpoint {
+				t.Errorf("expected control plane endpoint %q, but got %q", tc.expectedEndpoint, clusterConfig.ControlPlaneEndpoint)
+			}
+			if discovery := joinConfig.Discovery.BootstrapToken.APIServerEndpoint; discovery != tc.expectedEndpoint {
+				t.Errorf("expected discovery endpoint %q, but got %q", tc.expectedEndpoint, discovery)
+			}
+			if !reflect.DeepEqual(clusterConfig.APIServer.CertSANs, tc.expectedSANs) {
+				t.Errorf("expected cert SANs %v, but got %v", tc.expectedSANs, clusterConfig.APIServer.CertSANs)
+			}
+		})
+	}
+}
+
+func TestNewConfigAPIServerExtraVolumes(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name            string
+		extraArgs       map[string]string
+		features        kubeoneapi.Features
+		expectedVolumes []kubeadmv1beta1.HostPathMount
+	}{
+		{
+			name:            "no host files",
+			expectedVolumes: []kubeadmv1beta1.HostPathMount{},
+		},
+		{
+			name:      "audit policy",
+			extraArgs: map[string]string{"audit-policy-file": "/etc/kubeone/audit-policy.yaml"},
+			expectedVolumes: []kubeadmv1beta1.HostPathMount{
+				{Name: "audit-policy", HostPath: "/etc/kubeone/audit-policy.yaml", MountPath: "/etc/kubeone/audit-policy.yaml", ReadOnly: true, PathType: corev1.HostPathFile},
+			},
+		},
+		{
+			name: "tls certificate and key",
+			extraArgs: map[string]string{
+				"tls-cert-file":        "/etc/kubeone/apiserver.crt",
+				"tls-private-key-file": "/etc/kubeone/apiserver.key",
+			},
+			expectedVolumes: []kubeadmv1beta1.HostPathMount{
+				{Name: "tls-cert", HostPath: "/etc/kubeone/apiserver.crt", MountPath: "/etc/kubeone/apiserver.crt", ReadOnly: true, PathType: corev1.HostPathFile},
+				{Name: "tls-private-key", HostPath: "/etc/kubeone/apiserver.key", MountPath: "/etc/kubeone/apiserver.key", ReadOnly: true, PathType: corev1.HostPathFile},
+			},
+		},
+		{
+			name: "kubeadm managed tls certificate and key",
+			extraArgs: map[string]string{
+				"tls-cert-file":        "/etc/kubernetes/pki/apiserver.crt",
+				"tls-private-key-file": "/etc/kubernetes/pki/apiserver.key",
+			},
+			expectedVolumes: []kubeadmv1beta1.HostPathMount{},
+		},
+		{
+			name: "oidc ca",
+			features: kubeoneapi.Features{
+				OpenIDConnect: &kubeoneapi.OpenIDConnect{
+					Enable: true,
+					Config: kubeoneapi.OpenIDConnectConfig{IssuerURL: "https://dex.example.com", ClientID: "kubernetes", CAFile: "/etc/kubeone/oidc-ca.crt"},
+				},
+			},
+			expectedVolumes: []kubeadmv1beta1.HostPathMount{
+				{Name: "oidc-ca", HostPath: "/etc/kubeone/oidc-ca.crt", MountPath: "/etc/kubeone/oidc-ca.crt", ReadOnly: true, PathType: corev1.HostPathFile},
+			},
+		},
+		{
+			name:      "audit log directory",
+			extraArgs: map[string]string{"audit-log-path": "/var/log/kubernetes/audit.log"},
+			expectedVolumes: []kubeadmv1beta1.HostPathMount{
+				{Name: "audit-log", HostPath: "/var/log/kubernetes", MountPath: "/var/log/kubernetes", PathType: corev1.HostPathDirectoryOrCreate},
+			},
+		},
+		{
+			name:            "audit log to stdout",
+			extraArgs:       map[string]string{"audit-log-path": "-"},
+			expectedVolumes: []kubeadmv1beta1.HostPathMount{},
+		},
+		{
+			name:            "kubeadm managed files are mounted already",
+			extraArgs:       map[string]string{"audit-policy-file": "/etc/kubernetes/pki/audit-policy.yaml"},
+			expectedVolumes: []kubeadmv1beta1.HostPathMount{},
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cluster := &kubeoneapi.KubeOneCluster{Name: "test", APIEndpoint: kubeoneapi.APIEndpoint{Host: "lb.example.com", Port: 6443}}
+			cluster.ComponentConfig.APIServer.ExtraArgs = tc.extraArgs
+			cluster.Features = tc.features
+			_, clusterConfig := testConfigs(t, cluster)
+
+			if !reflect.DeepEqual(clusterConfig.APIServer.ExtraVolumes, tc.expectedVolumes) {
+				t.Errorf("expected extra volumes %+v, but got %+v", tc.expectedVolumes, clusterConfig.APIServer.ExtraVolumes)
+			}
+		})
+	}
+}
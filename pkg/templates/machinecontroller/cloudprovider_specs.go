@@ -18,27 +18,51 @@ package machinecontroller
 
 // AWSSpec holds cloudprovider spec for AWS
 type AWSSpec struct {
-	AMI              string            `json:"ami"`
-	AvailabilityZone string            `json:"availabilityZone"`
-	InstanceProfile  string            `json:"instanceProfile"`
-	Region           string            `json:"region"`
-	SecurityGroupIDs []string          `json:"securityGroupIDs"`
-	SubnetID         string            `json:"subnetId"`
-	VPCID            string            `json:"vpcId"`
-	InstanceType     *string           `json:"instanceType"`
-	DiskSize         *int              `json:"diskSize"`
-	Tags             map[string]string `json:"tags"`
+	AMI                           string                `json:"ami"`
+	AvailabilityZone              string                `json:"availabilityZone"`
+	InstanceProfile               string                `json:"instanceProfile"`
+	Region                        string                `json:"region"`
+	SecurityGroupIDs              []string              `json:"securityGroupIDs"`
+	SubnetID                      string                `json:"subnetId"`
+	SubnetIDs                     []string              `json:"subnetIds,omitempty"`
+	VPCID                         string                `json:"vpcId"`
+	InstanceType                  *string               `json:"instanceType"`
+	DiskSize                      *int                  `json:"diskSize"`
+	Tags                          map[string]string     `json:"tags"`
+	LaunchTemplateID              string                `json:"launchTemplateID,omitempty"`
+	LaunchTemplateVersion         string                `json:"launchTemplateVersion,omitempty"`
+	DisableSrcDstCheck            *bool                 `json:"disableSrcDstCheck,omitempty"`
+	MetadataOptions               *AWSMetadataOptions   `json:"metadataOptions,omitempty"`
+	DiskKMSKeyID                  string                `json:"diskKMSKeyID,omitempty"`
+	CapacityReservationID         string                `json:"capacityReservationID,omitempty"`
+	CapacityReservationPreference string                `json:"capacityReservationPreference,omitempty"`
+	NetworkInterfaces             []AWSNetworkInterface `json:"networkInterfaces,omitempty"`
+}
+
+// AWSMetadataOptions holds the instance metadata service options for AWS
+type AWSMetadataOptions struct {
+	HTTPTokens              string `json:"httpTokens,omitempty"`
+	HTTPPutResponseHopLimit int    `json:"httpPutResponseHopLimit,omitempty"`
+}
+
+// AWSNetworkInterface is an additional network interface attached to AWS
+// instances at launch
+type AWSNetworkInterface struct {
+	SubnetID         string   `json:"subnetId"`
+	SecurityGroupIDs []string `json:"securityGroupIDs,omitempty"`
+	DeviceIndex      int      `json:"deviceIndex"`
 }
 
 // DigitalOceanSpec holds cloudprovider spec for DigitalOcean
 type DigitalOceanSpec struct {
-	Region            string   `json:"region"`
-	Size              string   `json:"size"`
-	Backups           bool     `json:"backups"`
-	IPv6              bool     `json:"ipv6"`
-	PrivateNetworking bool     `json:"private_networking"`
-	Monitoring        bool     `json:"monitoring"`
-	Tags              []string `json:"tags"`
+	Region             string   `json:"region"`
+	Size               string   `json:"size"`
+	Backups            bool     `json:"backups"`
+	IPv6               bool     `json:"ipv6"`
+	PrivateNetworking  bool     `json:"private_networking"`
+	Monitoring         bool     `json:"monitoring"`
+	Tags               []string `json:"tags"`
+	SSHKeyFingerprints []string `json:"sshKeyFingerprints"`
 }
 
 // OpenStackSpec holds cloudprovider spec for OpenStack
@@ -51,6 +75,7 @@ type OpenStackSpec struct {
 	Network          string            `json:"network"`
 	Subnet           string            `json:"subnet"`
 	Tags             map[string]string `json:"tags"`
+	ServerGroupID    string            `json:"serverGroupID,omitempty"`
 }
 
 // GCESpec holds cloudprovider spec for GCE
@@ -67,13 +92,23 @@ type GCESpec struct {
 	Tags                  []string          `json:"tags"`
 	MultiZone             *bool             `json:"multizone"`
 	Regional              *bool             `json:"regional"`
+	ConfidentialComputing *bool             `json:"confidentialComputing"`
+	MinCPUPlatform        string            `json:"minCpuPlatform"`
+}
+
+// GCEDiskEncryptionKey is the customer-managed key used to encrypt the disks
+// of GCE instances
+type GCEDiskEncryptionKey struct {
+	KMSKeyName string `json:"kmsKeyName"`
 }
 
 // HetznerSpec holds cloudprovider spec for Hetzner
 type HetznerSpec struct {
-	ServerType string `json:"serverType"`
-	Datacenter string `json:"datacenter"`
-	Location   string `json:"location"`
+	ServerType string   `json:"serverType"`
+	Datacenter string   `json:"datacenter"`
+	Location   string   `json:"location"`
+	Image      string   `json:"image"`
+	SSHKeys    []string `json:"sshKeys"`
 }
 
 // PacketSpec holds cloudprovider spec for Packet
@@ -81,33 +116,40 @@ type PacketSpec struct {
 	ProjectID    string   `json:"projectID"`
 	Facilities   []string `json:"facilities"`
 	InstanceType string   `json:"instanceType"`
+	UserData     string   `json:"userData,omitempty"`
 }
 
 // VSphereSpec holds cloudprovider spec for vSphere
 type VSphereSpec struct {
-	AllowInsecure   bool   `json:"allowInsecure"`
-	Cluster         string `json:"cluster"`
-	CPUs            int    `json:"cpus"`
-	Datacenter      string `json:"datacenter"`
-	Datastore       string `json:"datastore"`
-	DiskSizeGB      *int   `json:"diskSizeGB,omitempty"`
-	Folder          string `json:"folder"`
-	MemoryMB        int    `json:"memoryMB"`
-	TemplateNetName string `json:"templateNetName,omitempty"`
-	TemplateVMName  string `json:"templateVMName"`
-	VMNetName       string `json:"vmNetName,omitempty"`
+	AllowInsecure           bool              `json:"allowInsecure"`
+	Cluster                 string            `json:"cluster"`
+	CPUs                    int               `json:"cpus"`
+	CustomAttributes        map[string]string `json:"customAttributes,omitempty"`
+	Datacenter              string            `json:"datacenter"`
+	Datastore               string            `json:"datastore"`
+	DiskSizeGB              *int              `json:"diskSizeGB,omitempty"`
+	Folder                  string            `json:"folder"`
+	MemoryMB                int               `json:"memoryMB"`
+	ResourcePoolPath        string            `json:"resourcePool,omitempty"`
+	TemplateNetName         string            `json:"templateNetName,omitempty"`
+	TemplateVMName          string            `json:"templateVMName"`
+	TemplateVMInventoryPath string            `json:"templateVMInventoryPath,omitempty"`
+	VMNetName               string            `json:"vmNetName,omitempty"`
 }
 
 // AzureSpec holds cloudprovider spec for Azure
 type AzureSpec struct {
-	AssignPublicIP    bool              `json:"assignPublicIP"`
-	AvailabilitySet   string            `json:"availabilitySet"`
-	Location          string            `json:"location"`
-	ResourceGroup     string            `json:"resourceGroup"`
-	RouteTableName    string            `json:"routeTableName"`
-	SecurityGroupName string            `json:"securityGroupName"`
-	SubnetName        string            `json:"subnetName"`
-	Tags              map[string]string `json:"tags"`
-	VMSize            string            `json:"vmSize"`
-	VNetName          string            `json:"vnetName"`
+	AssignPublicIP        bool              `json:"assignPublicIP"`
+	AvailabilitySet       string            `json:"availabilitySet"`
+	Location              string            `json:"location"`
+	ResourceGroup         string            `json:"resourceGroup"`
+	RouteTableName        string            `json:"routeTableName"`
+	SecurityGroupName     string            `json:"securityGroupName"`
+	SubnetName            string            `json:"subnetName"`
+	Tags                  map[string]string `json:"tags"`
+	VMSize                string            `json:"vmSize"`
+	VNetName              string            `json:"vnetName"`
+	UltraDiskEnabled      *bool             `json:"ultraDiskEnabled"`
+	DiskControllerType    string            `json:"diskControllerType"`
+	AcceleratedNetworking *bool             `json:"acceleratedNetworking"`
 }
@@ -791,7 +791,7 @@ func machineControllerDeployment(cluster *kubeoneapi.KubeOneCluster) (*appsv1.De
 					Containers: []corev1.Container{
 						{
 							Name:                     "machine-controller",
-							Image:                    "docker.io/kubermatic/machine-controller:" + MachineControllerTag,
+							Image:                    "docker.io/kubermatic/machine-controller:" + machineControllerTag(cluster),
 							ImagePullPolicy:          corev1.PullIfNotPresent,
 							Command:                  []string{"/usr/local/bin/machine-controller"},
 							Args:                     args,
@@ -831,6 +831,15 @@ func machineControllerDeployment(cluster *kubeoneapi.KubeOneCluster) (*appsv1.De
 	}, nil
 }
 
+// machineControllerTag returns the machine-controller image tag, which is
+// the pinned version if given and MachineControllerTag otherwise
+func machineControllerTag(cluster *kubeoneapi.KubeOneCluster) string {
+	if cluster.MachineController != nil && cluster.MachineController.Version != "" {
+		return cluster.MachineController.Version
+	}
+	return MachineControllerTag
+}
+
 func getEnvVarCredentials(cluster *kubeoneapi.KubeOneCluster) []corev1.EnvVar {
 	env := make([]corev1.EnvVar, 0)
 
@@ -90,6 +90,14 @@ func createMachineDeployment(cluster *kubeoneapi.KubeOneCluster, workerset kubeo
 	replicas := int32(*workerset.Replicas)
 	maxSurge := intstr.FromInt(1)
 	maxUnavailable := intstr.FromInt(0)
+	if workerset.RollingUpdate != nil {
+		if workerset.RollingUpdate.MaxSurge != nil {
+			maxSurge = *workerset.RollingUpdate.MaxSurge
+		}
+		if workerset.RollingUpdate.MaxUnavailable != nil {
+			maxUnavailable = *workerset.RollingUpdate.MaxUnavailable
+		}
+	}
 	minReadySeconds := int32(0)
 	workersetNameLabels := map[string]string{
 		"workerset": workerset.Name,
@@ -158,6 +166,10 @@ func machineSpec(cluster *kubeoneapi.KubeOneCluster, workerset kubeoneapi.Worker
 		}
 		awsSpec.Tags[tagName] = tagValue
 
+		if workerset.Config.DiskEncryptionKeyID != "" {
+			awsSpec.DiskKMSKeyID = workerset.Config.DiskEncryptionKeyID
+		}
+
 		// effectively overwrite specRaw retrieved earlier
 		specRaw, err = json.Marshal(awsSpec)
 		if err != nil {
@@ -171,5 +183,9 @@ func machineSpec(cluster *kubeoneapi.KubeOneCluster, workerset kubeoneapi.Worker
 		return nil, errors.Wrap(err, "unable to parse the workerset spec")
 	}
 
+	if workerset.Config.DiskEncryptionKeyID != "" && provider == kubeoneapi.CloudProviderNameGCE {
+		spec["diskEncryptionKey"] = map[string]interface{}{"kmsKeyName": workerset.Config.DiskEncryptionKeyID}
+	}
+
 	return spec, nil
 }
@@ -0,0 +1,112 @@
+/*
+Copyright 2019 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinecontroller
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	kubeoneapi "github.com/kubermatic/kubeone/pkg/apis/kubeone"
+)
+
+func TestMachineSpecDiskEncryptionKey(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name          string
+		provider      kubeoneapi.CloudProviderName
+		spec          string
+		keyID         string
+		expectedField string
+		expectedValue interface{}
+	}{
+		{
+			name:          "aws key overrides the kms key id",
+			provider:      kubeoneapi.CloudProviderNameAWS,
+			spec:          `{"diskKMSKeyID": "from-spec"}`,
+			keyID:         "from-workerset",
+			expectedField: "diskKMSKeyID",
+			expectedValue: "from-workerset",
+		},
+		{
+			name:          "aws kms key id is kept without key",
+			provider:      kubeoneapi.CloudProviderNameAWS,
+			spec:          `{"diskKMSKeyID": "from-spec"}`,
+			expectedField: "diskKMSKeyID",
+			expectedValue: "from-spec",
+		},
+		{
+			name:          "gce key is set as kms key name",
+			provider:      kubeoneapi.CloudProviderNameGCE,
+			spec:          `{"diskEncryptionKey": {"kmsKeyName": "from-spec"}}`,
+			keyID:         "from-workerset",
+			expectedField: "diskEncryptionKey",
+			expectedValue: map[string]interface{}{"kmsKeyName": "from-workerset"},
+		},
+		{
+			name:          "gce kms key name is kept without key",
+			provider:      kubeoneapi.CloudProviderNameGCE,
+			spec:          `{"diskEncryptionKey": {"kmsKeyName": "from-spec"}}`,
+			expectedField: "diskEncryptionKey",
+			expectedValue: map[string]interface{}{"kmsKeyName": "from-spec"},
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cluster := &kubeoneapi.KubeOneCluster{Name: "test"}
+			workerset := kubeoneapi.WorkerConfig{
+				Name: "pool",
+				Config: kubeoneapi.ProviderSpec{
+					CloudProviderSpec:   json.RawMessage(tc.spec),
+					DiskEncryptionKeyID: tc.keyID,
+				},
+			}
+
+			spec, err := machineSpec(cluster, workerset, tc.provider)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if value := spec[tc.expectedField]; !reflect.DeepEqual(value, tc.expectedValue) {
+				t.Fatalf("expected %s %v, but got %v", tc.expectedField, tc.expectedValue, value)
+			}
+		})
+	}
+}
+
+func TestMachineSpecAWSOmitsUnsetFields(t *testing.T) {
+	t.Parallel()
+
+	cluster := &kubeoneapi.KubeOneCluster{Name: "test"}
+	workerset := kubeoneapi.WorkerConfig{
+		Name: "pool",
+		Config: kubeoneapi.ProviderSpec{
+			CloudProviderSpec: json.RawMessage(`{"region": "eu-central-1"}`),
+		},
+	}
+
+	spec, err := machineSpec(cluster, workerset, kubeoneapi.CloudProviderNameAWS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{"subnetIds", "launchTemplateID", "launchTemplateVersion", "disableSrcDstCheck", "metadataOptions"} {
+		if value, ok := spec[field]; ok {
+			t.Errorf("expected %s to be omitted, but got %v", field, value)
+		}
+	}
+}
@@ -191,7 +191,7 @@ func webhookDeployment(cluster *kubeoneapi.KubeOneCluster) *appsv1.Deployment {
 	dep.Spec.Template.Spec.Containers = []corev1.Container{
 		{
 			Name:            "machine-controller-webhook",
-			Image:           "kubermatic/machine-controller:" + WebhookTag,
+			Image:           "kubermatic/machine-controller:" + machineControllerTag(cluster),
 			ImagePullPolicy: corev1.PullIfNotPresent,
 			Command:         []string{"/usr/local/bin/webhook"},
 			Args: []string{
@@ -18,10 +18,24 @@ package templates
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/ghodss/yaml"
 	"github.com/pkg/errors"
+
+	apiextensionsscheme "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/scheme"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/kubernetes/scheme"
+	apiregscheme "k8s.io/kube-aggregator/pkg/client/clientset_generated/clientset/scheme"
+	clusterscheme "sigs.k8s.io/cluster-api/pkg/client/clientset_generated/clientset/scheme"
 )
 
 // KubernetesToYAML properly encodes a list of resources as YAML.
@@ -40,9 +54,12 @@ func KubernetesToYAML(data []interface{}) (string, error) {
 			err         error
 		)
 
-		if str, ok := item.(string); ok {
-			encodedItem = []byte(strings.TrimSpace(str))
-		} else {
+		switch v := item.(type) {
+		case string:
+			encodedItem = []byte(strings.TrimSpace(v))
+		case []byte:
+			encodedItem = bytes.TrimSpace(v)
+		default:
 			encodedItem, err = yaml.Marshal(item)
 		}
 
@@ -60,6 +77,18 @@ func KubernetesToYAML(data []interface{}) (string, error) {
 	return buffer.String(), nil
 }
 
+// SortedStringMapKeys returns the keys of the given string map in sorted
+// order, so iterating over the map produces the same output on every run
+func SortedStringMapKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
 // MergeStringMap merges two string maps into destination string map
 func MergeStringMap(modified *bool, destination *map[string]string, required map[string]string) {
 	if *destination == nil {
@@ -73,3 +102,225 @@ func MergeStringMap(modified *bool, destination *map[string]string, required map
 		}
 	}
 }
+
+// StringMapConflict describes a key that has different values in the
+// destination and source maps
+type StringMapConflict struct {
+	Key      string
+	DstValue string
+	SrcValue string
+}
+
+// MergeStringMapStrict merges source string map into destination string map,
+// but unlike MergeStringMap it keeps the destination value for keys that have
+// different non-empty values in both maps and returns those keys as conflicts
+func MergeStringMapStrict(modified *bool, dst *map[string]string, src map[string]string) []StringMapConflict {
+	if *dst == nil {
+		*dst = map[string]string{}
+	}
+
+	var conflicts []StringMapConflict
+	for k, v := range src {
+		dstV, ok := (*dst)[k]
+		if ok && (dstV == v || v == "") {
+			continue
+		}
+		if ok && dstV != "" {
+			conflicts = append(conflicts, StringMapConflict{Key: k, DstValue: dstV, SrcValue: v})
+			continue
+		}
+
+		(*dst)[k] = v
+		*modified = true
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].Key < conflicts[j].Key
+	})
+
+	return conflicts
+}
+
+// MergeStringMapOrError merges required string map into destination string
+// map, but fails without modifying the destination map if any of its keys
+// would be overwritten with a different value
+func MergeStringMapOrError(dst *map[string]string, required map[string]string) error {
+	var conflicts []string
+	for _, k := range SortedStringMapKeys(required) {
+		if dstV, ok := (*dst)[k]; ok && dstV != required[k] {
+			conflicts = append(conflicts, fmt.Sprintf("%s (%q != %q)", k, dstV, required[k]))
+		}
+	}
+	if len(conflicts) > 0 {
+		return errors.Errorf("conflicting values for keys: %s", strings.Join(conflicts, ", "))
+	}
+
+	var modified bool
+	MergeStringMap(&modified, dst, required)
+
+	return nil
+}
+
+// CompactYAML re-serialises a multi-document YAML string in canonical form:
+// comments and empty documents are dropped, keys are sorted and every
+// document is indented with 2 spaces
+func CompactYAML(input string) (string, error) {
+	var buffer bytes.Buffer
+
+	for i, doc := range splitYAMLDocuments(input) {
+		jsonDoc, err := yaml.YAMLToJSON([]byte(doc))
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to parse document %d", i)
+		}
+		if bytes.Equal(jsonDoc, []byte("null")) {
+			continue
+		}
+
+		yamlDoc, err := yaml.JSONToYAML(jsonDoc)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to marshal document %d", i)
+		}
+
+		if buffer.Len() > 0 {
+			buffer.WriteString("---\n")
+		}
+		buffer.Write(yamlDoc)
+	}
+
+	return buffer.String(), nil
+}
+
+// YAMLDocumentCount returns the number of non-empty documents in a
+// multi-document YAML string, e.g. as produced by KubernetesToYAML. Only
+// unindented "---" lines separate documents, so separators inside block
+// scalars are not counted.
+func YAMLDocumentCount(yaml string) int {
+	return len(nonEmptyYAMLDocuments(yaml))
+}
+
+// ExtractYAMLDocument returns the non-empty document with the given index
+// from a multi-document YAML string, e.g. as produced by KubernetesToYAML.
+// Documents are counted the same way as by YAMLDocumentCount, the returned
+// document is stripped of surrounding whitespace and ends with a newline.
+func ExtractYAMLDocument(yaml string, index int) (string, error) {
+	docs := nonEmptyYAMLDocuments(yaml)
+	if index < 0 || index >= len(docs) {
+		return "", errors.Errorf("document index %d out of range, found %d documents", index, len(docs))
+	}
+
+	return strings.TrimSpace(docs[index]) + "\n", nil
+}
+
+// nonEmptyYAMLDocuments returns the documents of a multi-document YAML string
+// which contain anything other than whitespace and comments
+func nonEmptyYAMLDocuments(yaml string) []string {
+	var docs []string
+	for _, doc := range splitYAMLDocuments(yaml) {
+		for _, line := range strings.Split(doc, "\n") {
+			line = strings.TrimSpace(line)
+			if line != "" && !strings.HasPrefix(line, "#") {
+				docs = append(docs, doc)
+				break
+			}
+		}
+	}
+
+	return docs
+}
+
+// splitYAMLDocuments splits a multi-document YAML string on "---" separators
+func splitYAMLDocuments(input string) []string {
+	var (
+		docs    []string
+		current strings.Builder
+	)
+
+	for _, line := range strings.Split(input, "\n") {
+		if strings.TrimRight(line, " \t\r") == "---" {
+			docs = append(docs, current.String())
+			current.Reset()
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+	}
+
+	return append(docs, current.String())
+}
+
+var (
+	registerSchemesOnce sync.Once
+	registerSchemesErr  error
+)
+
+// RegisterSchemes registers all kinds deployed by KubeOne, that are not
+// Kubernetes built-ins, with the client-go scheme. It is safe to be called
+// multiple times, the kinds are only registered once.
+func RegisterSchemes() error {
+	registerSchemesOnce.Do(func() {
+		for _, addToScheme := range []func(*runtime.Scheme) error{
+			clusterscheme.AddToScheme,
+			apiextensionsscheme.AddToScheme,
+			apiregscheme.AddToScheme,
+		} {
+			if err := addToScheme(scheme.Scheme); err != nil {
+				registerSchemesErr = errors.Wrap(err, "failed to register scheme")
+				return
+			}
+		}
+	})
+
+	return registerSchemesErr
+}
+
+// ValidateYAML validates every document of a multi-document YAML string, e.g.
+// as produced by KubernetesToYAML, against the Go types of its apiVersion and
+// kind. Unknown kinds, unknown or mistyped fields and missing names are
+// reported for all documents at once.
+func ValidateYAML(yamlDocs string) error {
+	if err := RegisterSchemes(); err != nil {
+		return err
+	}
+
+	var errs []error
+	for i, doc := range nonEmptyYAMLDocuments(yamlDocs) {
+		if err := validateYAMLDocument(doc); err != nil {
+			errs = append(errs, errors.Wrapf(err, "document %d", i))
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+func validateYAMLDocument(doc string) error {
+	jsonDoc, err := yaml.YAMLToJSON([]byte(doc))
+	if err != nil {
+		return errors.Wrap(err, "failed to parse YAML")
+	}
+
+	var typeMeta metav1.TypeMeta
+	if err = json.Unmarshal(jsonDoc, &typeMeta); err != nil {
+		return errors.Wrap(err, "failed to parse apiVersion and kind")
+	}
+	if typeMeta.APIVersion == "" || typeMeta.Kind == "" {
+		return errors.New("apiVersion and kind are required")
+	}
+
+	gvk := schema.FromAPIVersionAndKind(typeMeta.APIVersion, typeMeta.Kind)
+	obj, err := scheme.Scheme.New(gvk)
+	if err != nil {
+		return errors.Errorf("unknown kind %s", gvk)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(jsonDoc))
+	dec.DisallowUnknownFields()
+	if err = dec.Decode(obj); err != nil {
+		return errors.Wrapf(err, "invalid %s", typeMeta.Kind)
+	}
+
+	if accessor, err := meta.Accessor(obj); err == nil && accessor.GetName() == "" {
+		return errors.Errorf("%s has no name", typeMeta.Kind)
+	}
+
+	return nil
+}
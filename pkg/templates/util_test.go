@@ -0,0 +1,371 @@
+/*
+Copyright 2019 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMergeStringMapStrict(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name              string
+		dst               map[string]string
+		src               map[string]string
+		expectedDst       map[string]string
+		expectedConflicts []StringMapConflict
+		expectedModified  bool
+	}{
+		{
+			name:             "nil destination",
+			dst:              nil,
+			src:              map[string]string{"a": "1"},
+			expectedDst:      map[string]string{"a": "1"},
+			expectedModified: true,
+		},
+		{
+			name:             "equal values",
+			dst:              map[string]string{"a": "1"},
+			src:              map[string]string{"a": "1"},
+			expectedDst:      map[string]string{"a": "1"},
+			expectedModified: false,
+		},
+		{
+			name:             "empty values are not conflicts",
+			dst:              map[string]string{"a": "", "b": "2"},
+			src:              map[string]string{"a": "1", "b": ""},
+			expectedDst:      map[string]string{"a": "1", "b": "2"},
+			expectedModified: true,
+		},
+		{
+			name:        "conflicting values",
+			dst:         map[string]string{"a": "1", "b": "2"},
+			src:         map[string]string{"a": "3", "b": "4", "c": "5"},
+			expectedDst: map[string]string{"a": "1", "b": "2", "c": "5"},
+			expectedConflicts: []StringMapConflict{
+				{Key: "a", DstValue: "1", SrcValue: "3"},
+				{Key: "b", DstValue: "2", SrcValue: "4"},
+			},
+			expectedModified: true,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var modified bool
+			dst := tc.dst
+			conflicts := MergeStringMapStrict(&modified, &dst, tc.src)
+			if !reflect.DeepEqual(dst, tc.expectedDst) {
+				t.Errorf("expected destination %v, but got %v", tc.expectedDst, dst)
+			}
+			if !reflect.DeepEqual(conflicts, tc.expectedConflicts) {
+				t.Errorf("expected conflicts %v, but got %v", tc.expectedConflicts, conflicts)
+			}
+			if modified != tc.expectedModified {
+				t.Errorf("expected modified to be %t, but got %t", tc.expectedModified, modified)
+			}
+		})
+	}
+}
+
+func TestSortedStringMapKeys(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name         string
+		m            map[string]string
+		expectedKeys []string
+	}{
+		{
+			name:         "nil map",
+			m:            nil,
+			expectedKeys: []string{},
+		},
+		{
+			name:         "multiple keys",
+			m:            map[string]string{"c": "1", "a": "2", "b": "3"},
+			expectedKeys: []string{"a", "b", "c"},
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			keys := SortedStringMapKeys(tc.m)
+			if !reflect.DeepEqual(keys, tc.expectedKeys) {
+				t.Errorf("expected keys %v, but got %v", tc.expectedKeys, keys)
+			}
+		})
+	}
+}
+
+func TestCompactYAML(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name           string
+		input          string
+		expectedOutput string
+		expectedError  bool
+	}{
+		{
+			name:           "sorts keys and strips comments",
+			input:          "# comment\nkind: ConfigMap\napiVersion: v1 # inline\nmetadata:\n    name: test\n",
+			expectedOutput: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: test\n",
+		},
+		{
+			name:           "multiple documents",
+			input:          "b: 1\na: 2\n\n---\n# only a comment\n---\nc:\n- d\n---\n",
+			expectedOutput: "a: 2\nb: 1\n---\nc:\n- d\n",
+		},
+		{
+			name:          "invalid document",
+			input:         "a: [b\n",
+			expectedError: true,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			output, err := CompactYAML(tc.input)
+			if tc.expectedError != (err != nil) {
+				t.Fatalf("expected error to be %t, but got %v", tc.expectedError, err)
+			}
+			if output != tc.expectedOutput {
+				t.Errorf("expected output %q, but got %q", tc.expectedOutput, output)
+			}
+		})
+	}
+}
+
+func TestKubernetesToYAML(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name           string
+		data           []interface{}
+		expectedOutput string
+	}{
+		{
+			name:           "string item",
+			data:           []interface{}{"  a: 1\n"},
+			expectedOutput: "a: 1\n---\n",
+		},
+		{
+			name:           "byte slice item",
+			data:           []interface{}{[]byte("\na: 1\n")},
+			expectedOutput: "a: 1\n---\n",
+		},
+		{
+			name:           "map item",
+			data:           []interface{}{map[string]int{"a": 1}},
+			expectedOutput: "a: 1\n\n---\n",
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			output, err := KubernetesToYAML(tc.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != tc.expectedOutput {
+				t.Errorf("expected output %q, but got %q", tc.expectedOutput, output)
+			}
+		})
+	}
+}
+
+func TestMergeStringMapOrError(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name        string
+		dst         map[string]string
+		required    map[string]string
+		expectedDst map[string]string
+		expectError bool
+	}{
+		{
+			name:        "nil destination",
+			required:    map[string]string{"a": "1"},
+			expectedDst: map[string]string{"a": "1"},
+		},
+		{
+			name:        "same values",
+			dst:         map[string]string{"a": "1", "b": "2"},
+			required:    map[string]string{"a": "1", "c": "3"},
+			expectedDst: map[string]string{"a": "1", "b": "2", "c": "3"},
+		},
+		{
+			name:        "conflicting values",
+			dst:         map[string]string{"app.kubernetes.io/managed-by": "kubeone"},
+			required:    map[string]string{"app.kubernetes.io/managed-by": "helm", "c": "3"},
+			expectedDst: map[string]string{"app.kubernetes.io/managed-by": "kubeone"},
+			expectError: true,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			dst := tc.dst
+			err := MergeStringMapOrError(&dst, tc.required)
+			if tc.expectError != (err != nil) {
+				t.Fatalf("expected error to be %t, but got %v", tc.expectError, err)
+			}
+			if !reflect.DeepEqual(dst, tc.expectedDst) {
+				t.Errorf("expected destination %v, but got %v", tc.expectedDst, dst)
+			}
+		})
+	}
+}
+
+func TestYAMLDocumentCount(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name          string
+		yaml          string
+		expectedCount int
+	}{
+		{
+			name:          "empty",
+			yaml:          "",
+			expectedCount: 0,
+		},
+		{
+			name:          "single document",
+			yaml:          "a: 1\n",
+			expectedCount: 1,
+		},
+		{
+			name:          "trailing separator and comment-only document",
+			yaml:          "a: 1\n---\n# comment\n---\nb: 2\n---\n",
+			expectedCount: 2,
+		},
+		{
+			name:          "separator inside block scalar",
+			yaml:          "a: |\n  ---\n  text\n---\nb: 2\n",
+			expectedCount: 2,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if count := YAMLDocumentCount(tc.yaml); count != tc.expectedCount {
+				t.Errorf("expected %d documents, but got %d", tc.expectedCount, count)
+			}
+		})
+	}
+}
+
+func TestExtractYAMLDocument(t *testing.T) {
+	t.Parallel()
+
+	multiDoc := "a: 1\n\n---\n# comment\n---\nb: |\n  ---\n  text\n---\n"
+
+	testcases := []struct {
+		name          string
+		index         int
+		expectedDoc   string
+		expectedError bool
+	}{
+		{
+			name:        "first document",
+			index:       0,
+			expectedDoc: "a: 1\n",
+		},
+		{
+			name:        "comment-only documents are skipped",
+			index:       1,
+			expectedDoc: "b: |\n  ---\n  text\n",
+		},
+		{
+			name:          "out of range",
+			index:         2,
+			expectedError: true,
+		},
+		{
+			name:          "negative index",
+			index:         -1,
+			expectedError: true,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := ExtractYAMLDocument(multiDoc, tc.index)
+			if (err != nil) != tc.expectedError {
+				t.Fatalf("expected error %t, but got %v", tc.expectedError, err)
+			}
+			if doc != tc.expectedDoc {
+				t.Errorf("expected document %q, but got %q", tc.expectedDoc, doc)
+			}
+		})
+	}
+}
+
+func TestValidateYAML(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name           string
+		yaml           string
+		expectedErrors []string
+	}{
+		{
+			name: "valid documents",
+			yaml: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\ndata:\n  key: value\n---\n" +
+				"apiVersion: cluster.k8s.io/v1alpha1\nkind: MachineDeployment\nmetadata:\n  name: pool\n",
+		},
+		{
+			name: "all invalid documents are reported",
+			yaml: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\ndatas: {}\n---\n" +
+				"apiVersion: apps/v2\nkind: Deployment\nmetadata:\n  name: b\n---\n" +
+				"apiVersion: v1\nkind: Service\n---\n" +
+				"metadata:\n  name: d\n",
+			expectedErrors: []string{
+				`document 0: invalid ConfigMap`,
+				`document 1: unknown kind apps/v2, Kind=Deployment`,
+				`document 2: Service has no name`,
+				`document 3: apiVersion and kind are required`,
+			},
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateYAML(tc.yaml)
+			if len(tc.expectedErrors) == 0 {
+				if err != nil {
+					t.Fatalf("expected no error, but got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected errors %v, but got none", tc.expectedErrors)
+			}
+			for _, expected := range tc.expectedErrors {
+				if !strings.Contains(err.Error(), expected) {
+					t.Errorf("expected error to contain %q, but got %v", expected, err)
+				}
+			}
+		})
+	}
+}
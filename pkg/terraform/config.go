@@ -17,182 +17,1433 @@ limitations under the License.
 package terraform
 
 import (
+	"bytes"
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
 
 	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	kubeonev1alpha1 "github.com/kubermatic/kubeone/pkg/apis/kubeone/v1alpha1"
+	"github.com/kubermatic/kubeone/pkg/templates"
 	"github.com/kubermatic/kubeone/pkg/templates/machinecontroller"
 )
 
 type controlPlane struct {
-	ClusterName       string   `json:"cluster_name"`
-	CloudProvider     *string  `json:"cloud_provider"`
-	PublicAddress     []string `json:"public_address"`
-	PrivateAddress    []string `json:"private_address"`
-	SSHUser           string   `json:"ssh_user"`
-	SSHPort           string   `json:"ssh_port"`
-	SSHPrivateKeyFile string   `json:"ssh_private_key_file"`
-	SSHAgentSocket    string   `json:"ssh_agent_socket"`
+	ClusterName           string            `json:"cluster_name"`
+	CloudProvider         *string           `json:"cloud_provider"`
+	PublicAddress         []string          `json:"public_address"`
+	PrivateAddress        []string          `json:"private_address"`
+	SSHUser               string            `json:"ssh_user"`
+	SSHPort               string            `json:"ssh_port"`
+	SSHPrivateKeyFile     string            `json:"ssh_private_key_file"`
+	SSHAgentSocket        string            `json:"ssh_agent_socket"`
+	NodeLabels            map[string]string `json:"node_labels"`
+	NodeTaints            []string          `json:"node_taints"`
+	AdditionalSANs        []string          `json:"additional_sans"`
+	KubeletExtraConfig    map[string]string `json:"kubelet_extra_config"`
+	PrivateNetworkGateway string            `json:"private_network_gateway"`
+
+	// annotations are set using AnnotateHosts, they are not part of the
+	// terraform output
+	annotations map[string]string
+}
+
+type gceConfig struct {
+	ProjectID string `json:"project_id"`
+}
+
+type openStackConfig struct {
+	UseOctavia          *bool  `json:"use_octavia"`
+	LBFloatingNetworkID string `json:"lb_floating_network_id"`
+}
+
+// Config represents configuration in the terraform output format
+type Config struct {
+	KubeOneAPI struct {
+		Value struct {
+			Endpoint         string `json:"endpoint"`
+			InternalEndpoint string `json:"internal_endpoint"`
+		} `json:"value"`
+	} `json:"kubeone_api"`
+
+	KubeOneHosts struct {
+		Value struct {
+			ControlPlane               []controlPlane    `json:"control_plane"`
+			PodCIDR                    string            `json:"pod_cidr"`
+			ServiceCIDR                string            `json:"service_cidr"`
+			ExternalCloudProvider      *bool             `json:"external_cloud_provider"`
+			APIServerExtraArgs         map[string]string `json:"apiserver_extra_args"`
+			ControllerManagerExtraArgs map[string]string `json:"controller_manager_extra_args"`
+			OpenStack                  openStackConfig   `json:"openstack"`
+			GCE                        gceConfig         `json:"gce"`
+			MachineControllerVersion   string            `json:"machine_controller_version"`
+			MachineControllerEnabled   *bool             `json:"machine_controller_enabled"`
+			CCMVersion                 string            `json:"cloud_controller_manager_version"`
+			CNIPlugin                  string            `json:"cni_plugin"`
+			AuditLogPath               string            `json:"audit_log_path"`
+			AuditPolicyFile            string            `json:"audit_policy_file"`
+			APIServerCertFile          string            `json:"apiserver_cert_file"`
+			APIServerKeyFile           string            `json:"apiserver_key_file"`
+			EtcdDataDir                string            `json:"etcd_data_dir"`
+			EtcdCompactionInterval     string            `json:"etcd_compaction_interval"`
+			PodSecurityStandard        string            `json:"pod_security_standard"`
+			OIDCIssuerURL              string            `json:"oidc_issuer_url"`
+			OIDCClientID               string            `json:"oidc_client_id"`
+			OIDCCAFile                 string            `json:"oidc_ca_file"`
+			OIDCGroupsClaim            string            `json:"oidc_groups_claim"`
+		} `json:"value"`
+	} `json:"kubeone_hosts"`
+
+	KubeOneWorkers struct {
+		Value map[string][]WorkerSetConfig `json:"value"`
+	} `json:"kubeone_workers"`
+
+	KubeOneSSHHostKeys struct {
+		Value map[string]string `json:"value"`
+	} `json:"kubeone_ssh_host_keys"`
+
+	KubeOneCertSANs struct {
+		Value []string `json:"value"`
+	} `json:"kubeone_cert_sans"`
+
+	warnings []string
+}
+
+// ApplyErrors holds all errors encountered while applying the terraform
+// configuration, so all misconfigured hosts and workersets can be reported at
+// once
+type ApplyErrors []error
+
+func (e ApplyErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+type cloudProviderFlags struct {
+	key   string
+	value interface{}
+}
+
+// OutputEnvVar is the name of the environment variable holding the terraform
+// output, either as plain or as base64 encoded JSON
+const OutputEnvVar = "KUBEONE_TERRAFORM_OUTPUT"
+
+// NewConfigFromJSON creates a new config object from json
+func NewConfigFromJSON(j []byte) (c *Config, err error) {
+	c = &Config{}
+	return c, json.Unmarshal(j, c)
+}
+
+// NewConfigFromReader creates a new config object from the json-encoded
+// terraform output read from r, without loading it into memory first
+func NewConfigFromReader(r io.Reader) (*Config, error) {
+	return ParseConfig(r, ParseOptions{})
+}
+
+// ParseOptions controls how the terraform output is parsed
+type ParseOptions struct {
+	// Strict rejects unknown fields in the values of the KubeOne outputs
+	Strict bool
+	// Workspace is the name of the terraform workspace the output belongs
+	// to, it's included in the errors
+	Workspace string
+	// ValidateOnParse validates the control plane hosts and workersets right
+	// away instead of failing later in Apply
+	ValidateOnParse bool
+}
+
+// ParseConfig creates a new config object from the json-encoded terraform
+// output read from r
+func ParseConfig(r io.Reader, opts ParseOptions) (*Config, error) {
+	c, err := parseConfig(r, opts.Strict)
+	if err == nil && opts.ValidateOnParse {
+		err = c.validate()
+	}
+	if err != nil {
+		if opts.Workspace != "" {
+			return nil, errors.Wrapf(err, "terraform workspace %q", opts.Workspace)
+		}
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func parseConfig(r io.Reader, strict bool) (*Config, error) {
+	c := &Config{}
+	if !strict {
+		if err := json.NewDecoder(r).Decode(c); err != nil {
+			return nil, decodeError(err, "")
+		}
+		return c, nil
+	}
+
+	// every terraform output also has the sensitive and type keys, so only
+	// the values are decoded strictly
+	var outputs map[string]struct {
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.NewDecoder(r).Decode(&outputs); err != nil {
+		return nil, decodeError(err, "")
+	}
+
+	values := map[string]interface{}{
+		"kubeone_api":           &c.KubeOneAPI.Value,
+		"kubeone_hosts":         &c.KubeOneHosts.Value,
+		"kubeone_workers":       &c.KubeOneWorkers.Value,
+		"kubeone_ssh_host_keys": &c.KubeOneSSHHostKeys.Value,
+		"kubeone_cert_sans":     &c.KubeOneCertSANs.Value,
+	}
+	for key, value := range values {
+		output, ok := outputs[key]
+		if !ok || len(output.Value) == 0 {
+			continue
+		}
+
+		dec := json.NewDecoder(bytes.NewReader(output.Value))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(value); err != nil {
+			return nil, decodeError(err, key)
+		}
+	}
+
+	return c, nil
+}
+
+func decodeError(err error, key string) error {
+	if typeErr, ok := err.(*json.UnmarshalTypeError); ok && key == "" && typeErr.Field != "" {
+		key = strings.SplitN(typeErr.Field, ".", 2)[0]
+	}
+	if key != "" {
+		return errors.Wrapf(err, "failed to decode terraform output key %q", key)
+	}
+	return errors.Wrap(err, "failed to decode terraform output")
+}
+
+// validate checks that the control plane hosts and workersets can be applied
+func (c *Config) validate() error {
+	if !c.HasControlPlane() {
+		return errors.New("no control plane hosts are given")
+	}
+	if _, err := c.ControlPlaneHostConfigs(); err != nil {
+		return err
+	}
+	for _, name := range c.WorkerSetNames() {
+		if msgs := validation.IsDNS1123Subdomain(name); len(msgs) > 0 {
+			return errors.Errorf("invalid workerset name %q: %s", name, strings.Join(msgs, ", "))
+		}
+	}
+
+	return nil
+}
+
+// ToJSON returns the config as json-encoded terraform output, formatted the
+// same way as by `terraform output -json`, i.e. with sorted keys and indented
+// with 2 spaces
+func (c *Config) ToJSON() ([]byte, error) {
+	buf, err := json.Marshal(c)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal terraform output")
+	}
+
+	// struct fields are encoded in declaration order, going through a generic
+	// value sorts all keys
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	if err = dec.Decode(&v); err != nil {
+		return nil, errors.Wrap(err, "failed to decode terraform output")
+	}
+
+	buf, err = json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal terraform output")
+	}
+
+	return append(buf, '\n'), nil
+}
+
+// WriteToFile writes the config as json-encoded terraform output to the given
+// path. The file is written atomically, so readers never see a partial file.
+func (c *Config) WriteToFile(path string) error {
+	buf, err := c.ToJSON()
+	if err != nil {
+		return err
+	}
+
+	tmpFile, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path))
+	if err != nil {
+		return errors.Wrap(err, "failed to create temporary file")
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err = tmpFile.Write(buf); err != nil {
+		tmpFile.Close()
+		return errors.Wrap(err, "failed to write temporary file")
+	}
+	if err = tmpFile.Close(); err != nil {
+		return errors.Wrap(err, "failed to close temporary file")
+	}
+
+	if err = os.Rename(tmpFile.Name(), path); err != nil {
+		return errors.Wrapf(err, "failed to write %s", path)
+	}
+
+	return nil
+}
+
+// ConfigFromEnv creates a new config object from the terraform output found
+// in the KUBEONE_TERRAFORM_OUTPUT environment variable
+func ConfigFromEnv() (*Config, error) {
+	output := strings.TrimSpace(os.Getenv(OutputEnvVar))
+	if output == "" {
+		return nil, errors.Errorf("environment variable %s is not set", OutputEnvVar)
+	}
+
+	j := []byte(output)
+	// JSON objects always start with a curly brace, which is not a part of
+	// the base64 alphabet
+	if !strings.HasPrefix(output, "{") {
+		var err error
+		j, err = base64.StdEncoding.DecodeString(output)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode base64 encoded terraform output from %s", OutputEnvVar)
+		}
+	}
+
+	c, err := NewConfigFromJSON(j)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse terraform output from %s", OutputEnvVar)
+	}
+
+	return c, nil
+}
+
+// SSHHostKeys returns the known SSH host public keys of the control plane
+// hosts, keyed by the public address of the host
+func (c *Config) SSHHostKeys() (map[string]string, error) {
+	hostKeys := make(map[string]string, len(c.KubeOneSSHHostKeys.Value))
+	for address, hostKey := range c.KubeOneSSHHostKeys.Value {
+		if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(hostKey)); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse ssh host key for host %q", address)
+		}
+		hostKeys[address] = strings.TrimSpace(hostKey)
+	}
+
+	return hostKeys, nil
+}
+
+// WorkerSetNames returns the sorted names of the workersets
+func (c *Config) WorkerSetNames() []string {
+	names := make([]string, 0, len(c.KubeOneWorkers.Value))
+	for name := range c.KubeOneWorkers.Value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// WorkerSetByName returns the config of the workerset with the given name.
+// Terraform wraps the config of each workerset in a single element list, the
+// workerset is not found if the list has any other length.
+func (c *Config) WorkerSetByName(name string) (WorkerSetConfig, bool) {
+	values, ok := c.KubeOneWorkers.Value[name]
+	if !ok || len(values) != 1 {
+		return nil, false
+	}
+
+	return values[0], true
+}
+
+// Equals reports whether both configs result in the same cluster state when
+// applied. Unlike comparing the serialised JSON, it ignores formatting and
+// key order, as well as empty values that are ignored by Apply. The host
+// annotations set by AnnotateHosts are compared as well.
+func (c *Config) Equals(other *Config) bool {
+	if c == nil || other == nil {
+		return c == other
+	}
+
+	if !reflect.DeepEqual(c.hostAnnotations(), other.hostAnnotations()) {
+		return false
+	}
+
+	a, err := c.normalized()
+	if err != nil {
+		return false
+	}
+	b, err := other.normalized()
+	if err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// hostAnnotations returns the annotations of all control plane entries, with
+// empty annotations returned as nil
+func (c *Config) hostAnnotations() []map[string]string {
+	annotations := make([]map[string]string, 0, len(c.KubeOneHosts.Value.ControlPlane))
+	for _, cp := range c.KubeOneHosts.Value.ControlPlane {
+		if len(cp.annotations) == 0 {
+			annotations = append(annotations, nil)
+			continue
+		}
+		annotations = append(annotations, cp.annotations)
+	}
+
+	return annotations
+}
+
+// keepEmptyJSONKeys are the keys of pointer fields, which Apply treats
+// differently when set to an empty string than when unset
+var keepEmptyJSONKeys = map[string]bool{
+	"cloud_provider": true,
+}
+
+// normalized returns the config as a generic JSON value without the empty
+// values that are ignored by Apply. The workerset specs are kept as they are,
+// as their empty values are not ignored consistently.
+func (c *Config) normalized() (interface{}, error) {
+	buf, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var v map[string]interface{}
+	if err := json.Unmarshal(buf, &v); err != nil {
+		return nil, err
+	}
+
+	workers := v["kubeone_workers"]
+	delete(v, "kubeone_workers")
+	normalized := pruneEmptyJSONValues(v)
+
+	if len(c.KubeOneWorkers.Value) > 0 {
+		if normalized == nil {
+			normalized = map[string]interface{}{}
+		}
+		normalized.(map[string]interface{})["kubeone_workers"] = workers
+	}
+
+	return normalized, nil
+}
+
+func pruneEmptyJSONValues(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			if keepEmptyJSONKeys[k] && item != nil {
+				continue
+			}
+			item = pruneEmptyJSONValues(item)
+			if item == nil {
+				delete(val, k)
+				continue
+			}
+			val[k] = item
+		}
+		if len(val) == 0 {
+			return nil
+		}
+	case []interface{}:
+		for i, item := range val {
+			val[i] = pruneEmptyJSONValues(item)
+		}
+		if len(val) == 0 {
+			return nil
+		}
+	case string:
+		if val == "" {
+			return nil
+		}
+	}
+
+	return v
+}
+
+// Warnings returns the non-fatal problems found by the last Apply call
+func (c *Config) Warnings() []string {
+	return c.warnings
+}
+
+// HasControlPlane reports whether the terraform output has a control plane
+// section, which is required to access the control plane config
+func (c *Config) HasControlPlane() bool {
+	return len(c.KubeOneHosts.Value.ControlPlane) > 0
+}
+
+// HasWorkers reports whether the terraform output has any workersets
+func (c *Config) HasWorkers() bool {
+	return len(c.KubeOneWorkers.Value) > 0
+}
+
+// ControlPlaneCount returns the number of control plane hosts. It is 0 if
+// the control plane section has no addresses, in which case Apply keeps the
+// hosts from `config.yaml`.
+func (c *Config) ControlPlaneCount() int {
+	if !c.HasControlPlane() {
+		return 0
+	}
+
+	return len(c.KubeOneHosts.Value.ControlPlane[0].PublicAddress)
+}
+
+// ErrReplicasUnset is returned by WorkerReplicaTotal if the replicas of a
+// workerset are not given in the terraform output
+var ErrReplicasUnset = errors.New("workerset replicas are not set")
+
+// WorkerReplicaTotal returns the sum of the replicas of all workersets. The
+// returned error's cause is ErrReplicasUnset if there are no workersets or
+// any of them lacks the replicas.
+func (c *Config) WorkerReplicaTotal() (int, error) {
+	if !c.HasWorkers() {
+		return 0, ErrReplicasUnset
+	}
+
+	total := 0
+	for _, name := range c.WorkerSetNames() {
+		ws, ok := c.WorkerSetByName(name)
+		if !ok {
+			return 0, errors.Wrapf(ErrReplicasUnset, "workerset %q", name)
+		}
+		replicas, err := ws.GetReplicas()
+		if err != nil {
+			return 0, errors.Wrapf(err, "workerset %q", name)
+		}
+		if replicas == nil {
+			return 0, errors.Wrapf(ErrReplicasUnset, "workerset %q", name)
+		}
+		total += *replicas
+	}
+
+	return total, nil
+}
+
+// ControlPlaneClusterName returns the cluster name given in the terraform
+// output
+func (c *Config) ControlPlaneClusterName() (string, error) {
+	if !c.HasControlPlane() {
+		return "", errors.New("no control plane hosts are given")
+	}
+
+	return c.KubeOneHosts.Value.ControlPlane[0].ClusterName, nil
+}
+
+// ControlPlaneIPs returns copies of the public and private addresses of the
+// control plane hosts
+func (c *Config) ControlPlaneIPs() (public []string, private []string) {
+	if !c.HasControlPlane() {
+		return nil, nil
+	}
+
+	cp := c.KubeOneHosts.Value.ControlPlane[0]
+	return copyStrings(cp.PublicAddress), copyStrings(cp.PrivateAddress)
+}
+
+// WorkerSetSummary describes a workerset from the terraform output
+type WorkerSetSummary struct {
+	Name         string
+	Replicas     *int
+	InstanceType string
+	// Spec is the raw workerset config as given in the terraform output
+	Spec json.RawMessage
+}
+
+// FindWorkerSet returns the summary of the workerset with the given name. Like
+// WorkerSetByName, it only finds workersets that are applied by Apply.
+func (c *Config) FindWorkerSet(name string) (*WorkerSetSummary, bool) {
+	value, ok := c.WorkerSetByName(name)
+	if !ok {
+		return nil, false
+	}
+
+	ws := &WorkerSetSummary{
+		Name: name,
+		Spec: json.RawMessage(value),
+	}
+
+	var spec map[string]interface{}
+	if err := json.Unmarshal(ws.Spec, &spec); err != nil {
+		return ws, true
+	}
+	if replicas, ok := spec["replicas"].(float64); ok {
+		r := int(replicas)
+		ws.Replicas = &r
+	}
+	for _, key := range instanceTypeKeys {
+		if instanceType, ok := spec[key].(string); ok && instanceType != "" {
+			ws.InstanceType = instanceType
+			break
+		}
+	}
+
+	return ws, true
+}
+
+// String returns a condensed summary of the config for log and error
+// messages, e.g. `Config{provider: aws, controlPlane: 3 hosts, workers:
+// {pool-a: 3}}`. Workersets without replicas are shown with a replica count
+// of `?`.
+func (c *Config) String() string {
+	provider := "unset"
+	if c.HasControlPlane() && c.KubeOneHosts.Value.ControlPlane[0].CloudProvider != nil {
+		provider = *c.KubeOneHosts.Value.ControlPlane[0].CloudProvider
+	}
+
+	workers := make([]string, 0, len(c.KubeOneWorkers.Value))
+	for _, name := range c.WorkerSetNames() {
+		replicas := "?"
+		if ws, ok := c.FindWorkerSet(name); ok && ws.Replicas != nil {
+			replicas = strconv.Itoa(*ws.Replicas)
+		}
+		workers = append(workers, fmt.Sprintf("%s: %s", name, replicas))
+	}
+
+	return fmt.Sprintf("Config{provider: %s, controlPlane: %d hosts, workers: {%s}}", provider, c.ControlPlaneCount(), strings.Join(workers, ", "))
+}
+
+// instanceTypeKeys are the worker config keys holding the instance type, which
+// every cloud provider names differently
+var instanceTypeKeys = []string{"instanceType", "machineType", "serverType", "size", "flavor", "vmSize"}
+
+// SummaryTable returns a human-readable table of the cluster and workersets
+// described by the terraform output
+func (c *Config) SummaryTable() string {
+	var (
+		clusterName string
+		provider    string
+	)
+	if c.HasControlPlane() {
+		cp := c.KubeOneHosts.Value.ControlPlane[0]
+		clusterName = cp.ClusterName
+		if cp.CloudProvider != nil {
+			provider = *cp.CloudProvider
+		}
+	}
+	public, private := c.ControlPlaneIPs()
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "Cluster name:\t%s\n", clusterName)
+	fmt.Fprintf(w, "Cloud provider:\t%s\n", provider)
+	fmt.Fprintf(w, "Control plane public IPs:\t%s\n", strings.Join(public, ", "))
+	fmt.Fprintf(w, "Control plane private IPs:\t%s\n", strings.Join(private, ", "))
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "WORKERSET\tPROVIDER\tREPLICAS\tINSTANCE TYPE")
+	for _, name := range c.WorkerSetNames() {
+		replicas, instanceType := "-", "-"
+		if ws, ok := c.FindWorkerSet(name); ok {
+			if ws.Replicas != nil {
+				replicas = strconv.Itoa(*ws.Replicas)
+			}
+			if ws.InstanceType != "" {
+				instanceType = ws.InstanceType
+			}
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, provider, replicas, instanceType)
+	}
+
+	_ = w.Flush()
+	return buf.String()
+}
+
+// ApplyToHostConfigs updates the given hosts with the addresses and SSH
+// settings of the control plane hosts from the terraform output and returns
+// the updated copy. Hosts are matched by their position, hosts missing in the
+// given slice are added, while additional given hosts are kept unchanged.
+func (c *Config) ApplyToHostConfigs(hosts []kubeonev1alpha1.HostConfig) ([]kubeonev1alpha1.HostConfig, error) {
+	tfHosts, err := c.ControlPlaneHostConfigs()
+	if err != nil {
+		return nil, err
+	}
+
+	updated := make([]kubeonev1alpha1.HostConfig, len(hosts))
+	copy(updated, hosts)
+
+	for i, tfHost := range tfHosts {
+		if i >= len(updated) {
+			updated = append(updated, tfHost)
+			continue
+		}
+
+		host := &updated[i]
+		host.ID = i
+		host.PublicAddress = tfHost.PublicAddress
+		host.PrivateAddress = tfHost.PrivateAddress
+		if tfHost.SSHPort != 0 {
+			host.SSHPort = tfHost.SSHPort
+		}
+		if tfHost.SSHUsername != "" {
+			host.SSHUsername = tfHost.SSHUsername
+		}
+		if tfHost.SSHPrivateKeyFile != "" {
+			host.SSHPrivateKeyFile = tfHost.SSHPrivateKeyFile
+		}
+		if tfHost.SSHAgentSocket != "" {
+			host.SSHAgentSocket = tfHost.SSHAgentSocket
+		}
+		if tfHost.SSHHostPublicKey != "" {
+			host.SSHHostPublicKey = tfHost.SSHHostPublicKey
+		}
+		if tfHost.PrivateNetworkGateway != "" && host.PrivateNetworkGateway == "" {
+			host.PrivateNetworkGateway = tfHost.PrivateNetworkGateway
+		}
+		// annotations from `config.yaml` take precedence, they are merged
+		// into a copy to leave the given hosts unchanged
+		if len(tfHost.Annotations) > 0 {
+			annotations := copyStringMap(host.Annotations)
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			for k, v := range tfHost.Annotations {
+				if _, ok := annotations[k]; !ok {
+					annotations[k] = v
+				}
+			}
+			host.Annotations = annotations
+		}
+	}
+
+	return updated, nil
+}
+
+// ControlPlaneHostConfigs returns the host configs of all control plane hosts
+func (c *Config) ControlPlaneHostConfigs() ([]kubeonev1alpha1.HostConfig, error) {
+	hosts := make([]kubeonev1alpha1.HostConfig, 0, c.ControlPlaneCount())
+	for i := 0; i < c.ControlPlaneCount(); i++ {
+		host, err := c.ControlPlaneHostConfig(i)
+		if err != nil {
+			// all hosts share the same config, so there is no point in
+			// reporting the same error for every single one of them
+			return nil, err
+		}
+		hosts = append(hosts, *host)
+	}
+
+	return hosts, nil
+}
+
+// ControlPlaneHostConfig returns the config of the control plane host with
+// the given index, the same way as it's built by Apply
+func (c *Config) ControlPlaneHostConfig(idx int) (*kubeonev1alpha1.HostConfig, error) {
+	if !c.HasControlPlane() {
+		return nil, errors.New("no control plane hosts are given")
+	}
+
+	cp := c.KubeOneHosts.Value.ControlPlane[0]
+
+	if idx < 0 || idx >= len(cp.PublicAddress) {
+		return nil, errors.Errorf("control plane host index %d is out of range, %d hosts are given", idx, len(cp.PublicAddress))
+	}
+
+	var sshPort int
+	if cp.SSHPort != "" {
+		var err error
+		sshPort, err = strconv.Atoi(cp.SSHPort)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to convert ssh port string %q to int", cp.SSHPort)
+		}
+	}
+
+	hostKeys, err := c.SSHHostKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	publicIP := cp.PublicAddress[idx]
+	privateIP := publicIP
+	if idx < len(cp.PrivateAddress) {
+		privateIP = cp.PrivateAddress[idx]
+	}
+
+	var taints []corev1.Taint
+	for _, t := range cp.NodeTaints {
+		taint, err := parseTaint(t)
+		if err != nil {
+			return nil, err
+		}
+		taints = append(taints, taint)
+	}
+
+	return &kubeonev1alpha1.HostConfig{
+		ID:                    idx,
+		PublicAddress:         publicIP,
+		PrivateAddress:        privateIP,
+		SSHUsername:           cp.SSHUser,
+		SSHPort:               sshPort,
+		SSHPrivateKeyFile:     cp.SSHPrivateKeyFile,
+		SSHAgentSocket:        cp.SSHAgentSocket,
+		SSHHostPublicKey:      hostKeys[publicIP],
+		Labels:                cp.NodeLabels,
+		Taints:                taints,
+		KubeletExtraArgs:      cp.KubeletExtraConfig,
+		Annotations:           copyStringMap(cp.annotations),
+		PrivateNetworkGateway: cp.PrivateNetworkGateway,
+	}, nil
+}
+
+// AnnotateHosts attaches the given annotations to all control plane hosts,
+// so they are included in the host configs built by Apply. Annotations given
+// by earlier calls are kept unless overwritten.
+func (c *Config) AnnotateHosts(annotations map[string]string) error {
+	if !c.HasControlPlane() {
+		return errors.New("no control plane hosts are given")
+	}
+
+	for _, key := range templates.SortedStringMapKeys(annotations) {
+		if msgs := validation.IsQualifiedName(key); len(msgs) > 0 {
+			return errors.Errorf("invalid annotation key %q: %s", key, strings.Join(msgs, ", "))
+		}
+	}
+
+	for i := range c.KubeOneHosts.Value.ControlPlane {
+		cp := &c.KubeOneHosts.Value.ControlPlane[i]
+		if cp.annotations == nil {
+			cp.annotations = make(map[string]string, len(annotations))
+		}
+		for k, v := range annotations {
+			cp.annotations[k] = v
+		}
+	}
+
+	return nil
+}
+
+// copyStrings returns a copy of the given slice, or nil if it is nil
+func copyStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+
+	return append(make([]string, 0, len(s)), s...)
+}
+
+// copyStringMap returns a copy of the given map, or nil if it is nil
+func copyStringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+
+	return c
+}
+
+// parseTaint parses a taint in the key=value:Effect or key:Effect format,
+// the same one as used by kubectl
+func parseTaint(t string) (corev1.Taint, error) {
+	var taint corev1.Taint
+
+	sep := strings.LastIndex(t, ":")
+	if sep < 1 {
+		return taint, errors.Errorf("invalid taint %q, expected format is key=value:Effect", t)
+	}
+
+	taint.Effect = corev1.TaintEffect(t[sep+1:])
+	switch taint.Effect {
+	case corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+	default:
+		return taint, errors.Errorf("invalid taint %q, unsupported effect %q", t, taint.Effect)
+	}
+
+	kv := strings.SplitN(t[:sep], "=", 2)
+	taint.Key = kv[0]
+	if len(kv) == 2 {
+		taint.Value = kv[1]
+	}
+
+	return taint, nil
+}
+
+// Sections of the terraform output that can be applied separately using
+// ApplyPartial
+const (
+	// SectionAPI is the API endpoint and the certificate SANs
+	SectionAPI = "api"
+	// SectionNetworking is the pod and service subnets and the CNI plugin
+	SectionNetworking = "networking"
+	// SectionCluster is the cluster-wide config of the control plane
+	// components, which doesn't depend on the control plane hosts
+	SectionCluster = "cluster"
+	// SectionHosts is the control plane hosts, the cluster name and the cloud
+	// provider
+	SectionHosts = "hosts"
+	// SectionWorkers is the workersets and the machine-controller config
+	SectionWorkers = "workers"
+)
+
+// applySections are all sections in the order they are applied
+var applySections = []string{SectionAPI, SectionNetworking, SectionCluster, SectionHosts, SectionWorkers}
+
+// Apply adds the terraform configuration options to the given
+// cluster config.
+func (c *Config) Apply(cluster *kubeonev1alpha1.KubeOneCluster) error {
+	return c.ApplyPartial(cluster, applySections...)
 }
 
-// Config represents configuration in the terraform output format
-type Config struct {
-	KubeOneAPI struct {
-		Value struct {
-			Endpoint string `json:"endpoint"`
-		} `json:"value"`
-	} `json:"kubeone_api"`
+// ApplyConcurrent is the same as Apply, but updates the workersets in
+// parallel, using at most one goroutine per CPU. With a single CPU the
+// workersets are updated sequentially. Whether this is faster depends on the
+// number of workersets and CPUs, see BenchmarkApply and
+// BenchmarkApplyConcurrent.
+func (c *Config) ApplyConcurrent(cluster *kubeonev1alpha1.KubeOneCluster) error {
+	err := c.ApplyPartial(cluster, SectionAPI, SectionNetworking, SectionCluster, SectionHosts)
+	errs, ok := err.(ApplyErrors)
+	if err != nil && !ok {
+		return err
+	}
 
-	KubeOneHosts struct {
-		Value struct {
-			ControlPlane []controlPlane `json:"control_plane"`
-		} `json:"value"`
-	} `json:"kubeone_hosts"`
+	c.applyMachineController(cluster)
+	err = c.applyWorkersets(cluster, c.WorkerSetNames(), true)
+	if workerErrs, ok := err.(ApplyErrors); ok {
+		errs = append(errs, workerErrs...)
+	} else if err != nil {
+		return err
+	}
 
-	KubeOneWorkers struct {
-		Value map[string][]json.RawMessage `json:"value"`
-	} `json:"kubeone_workers"`
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
 }
 
-type cloudProviderFlags struct {
-	key   string
-	value interface{}
+// ApplyToWorkerSets adds the terraform configuration of the workersets with
+// the given names to the given cluster config. All other workersets, the
+// hosts and the API endpoint are left unchanged.
+func (c *Config) ApplyToWorkerSets(cluster *kubeonev1alpha1.KubeOneCluster, names []string) error {
+	c.warnings = nil
+
+	var selected []string
+	for _, name := range c.WorkerSetNames() {
+		if containsString(names, name) {
+			selected = append(selected, name)
+		}
+	}
+	for _, name := range names {
+		if !containsString(selected, name) {
+			return errors.Errorf("workerset %q not found in terraform output", name)
+		}
+	}
+
+	return c.applyWorkersets(cluster, selected, false)
 }
 
-// NewConfigFromJSON creates a new config object from json
-func NewConfigFromJSON(j []byte) (c *Config, err error) {
-	c = &Config{}
-	return c, json.Unmarshal(j, c)
+// ApplyPartial adds only the given sections of the terraform configuration
+// options to the given cluster config, e.g. to update the workersets without
+// touching the control plane hosts. Sections are always applied in the same
+// order, regardless of the order they are given in.
+func (c *Config) ApplyPartial(cluster *kubeonev1alpha1.KubeOneCluster, sections ...string) error {
+	c.warnings = nil
+
+	for _, section := range sections {
+		if !containsString(applySections, section) {
+			return errors.Errorf("unknown terraform output section %q", section)
+		}
+	}
+
+	if containsString(sections, SectionHosts) {
+		if !c.HasControlPlane() {
+			return errors.New("no control plane hosts are given")
+		}
+		if c.KubeOneHosts.Value.ControlPlane[0].CloudProvider == nil && cluster.CloudProvider.Name == "" {
+			return errors.New("cloud provider must be specified in terraform output or cluster config")
+		}
+	}
+
+	appliers := map[string]func(*kubeonev1alpha1.KubeOneCluster) error{
+		SectionAPI:        c.applyAPI,
+		SectionNetworking: c.applyNetworking,
+		SectionCluster:    c.applyCluster,
+		SectionHosts:      c.applyHosts,
+		SectionWorkers:    c.applyWorkers,
+	}
+
+	var errs ApplyErrors
+
+	for _, section := range applySections {
+		if !containsString(sections, section) {
+			continue
+		}
+
+		// ApplyErrors are collected, so that all problems are reported at
+		// once, any other error aborts
+		err := appliers[section](cluster)
+		if applyErrs, ok := err.(ApplyErrors); ok {
+			errs = append(errs, applyErrs...)
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
 }
 
-// Apply adds the terraform configuration options to the given
-// cluster config.
-func (c *Config) Apply(cluster *kubeonev1alpha1.KubeOneCluster) error {
+func (c *Config) applyAPI(cluster *kubeonev1alpha1.KubeOneCluster) error {
 	if c.KubeOneAPI.Value.Endpoint != "" {
 		cluster.APIEndpoint = kubeonev1alpha1.APIEndpoint{
-			Host: c.KubeOneAPI.Value.Endpoint,
+			Host:             c.KubeOneAPI.Value.Endpoint,
+			AlternativeNames: cluster.APIEndpoint.AlternativeNames,
+			InternalHost:     cluster.APIEndpoint.InternalHost,
 		}
 	}
 
-	if len(c.KubeOneHosts.Value.ControlPlane) == 0 {
-		return errors.New("no control plane hosts are given")
+	// Only set the internal endpoint if it was not configured yet to ensure
+	// config from `config.yaml` takes precedence
+	if c.KubeOneAPI.Value.InternalEndpoint != "" && cluster.APIEndpoint.InternalHost == "" {
+		cluster.APIEndpoint.InternalHost = c.KubeOneAPI.Value.InternalEndpoint
+	}
+
+	sans := append([]string{}, c.KubeOneCertSANs.Value...)
+	if c.HasControlPlane() {
+		sans = append(sans, c.KubeOneHosts.Value.ControlPlane[0].AdditionalSANs...)
+	}
+	for _, name := range sans {
+		if !containsString(cluster.APIEndpoint.AlternativeNames, name) {
+			cluster.APIEndpoint.AlternativeNames = append(cluster.APIEndpoint.AlternativeNames, name)
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) applyNetworking(cluster *kubeonev1alpha1.KubeOneCluster) error {
+	if c.KubeOneHosts.Value.PodCIDR != "" {
+		cluster.ClusterNetwork.PodSubnet = c.KubeOneHosts.Value.PodCIDR
+	}
+
+	if c.KubeOneHosts.Value.ServiceCIDR != "" {
+		cluster.ClusterNetwork.ServiceSubnet = c.KubeOneHosts.Value.ServiceCIDR
+	}
+
+	// Only set the CNI plugin if it was not configured yet to ensure config
+	// from `config.yaml` takes precedence
+	if c.KubeOneHosts.Value.CNIPlugin != "" && cluster.ClusterNetwork.CNI == nil {
+		cluster.ClusterNetwork.CNI = &kubeonev1alpha1.CNI{
+			Provider: kubeonev1alpha1.CNIProvider(c.KubeOneHosts.Value.CNIPlugin),
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) applyCluster(cluster *kubeonev1alpha1.KubeOneCluster) error {
+	// Extra args from `config.yaml` take precedence
+	setDefaultExtraArgs(&cluster.ComponentConfig.APIServer.ExtraArgs, c.KubeOneHosts.Value.APIServerExtraArgs)
+	setDefaultExtraArgs(&cluster.ComponentConfig.ControllerManager.ExtraArgs, c.KubeOneHosts.Value.ControllerManagerExtraArgs)
+
+	auditArgs := map[string]string{}
+	if c.KubeOneHosts.Value.AuditLogPath != "" {
+		auditArgs["audit-log-path"] = c.KubeOneHosts.Value.AuditLogPath
+	}
+	if c.KubeOneHosts.Value.AuditPolicyFile != "" {
+		auditArgs["audit-policy-file"] = c.KubeOneHosts.Value.AuditPolicyFile
+	}
+	setDefaultExtraArgs(&cluster.ComponentConfig.APIServer.ExtraArgs, auditArgs)
+
+	if c.KubeOneHosts.Value.APIServerCertFile != "" || c.KubeOneHosts.Value.APIServerKeyFile != "" {
+		if c.KubeOneHosts.Value.APIServerCertFile == "" || c.KubeOneHosts.Value.APIServerKeyFile == "" {
+			return errors.New("both apiserver_cert_file and apiserver_key_file must be given")
+		}
+		setDefaultExtraArgs(&cluster.ComponentConfig.APIServer.ExtraArgs, map[string]string{
+			"tls-cert-file":        c.KubeOneHosts.Value.APIServerCertFile,
+			"tls-private-key-file": c.KubeOneHosts.Value.APIServerKeyFile,
+		})
+	}
+
+	// Only set the etcd data dir if it was not configured yet to ensure config
+	// from `config.yaml` takes precedence
+	if c.KubeOneHosts.Value.EtcdDataDir != "" && cluster.ComponentConfig.Etcd.DataDir == "" {
+		cluster.ComponentConfig.Etcd.DataDir = c.KubeOneHosts.Value.EtcdDataDir
+	}
+	if c.KubeOneHosts.Value.EtcdCompactionInterval != "" && cluster.ComponentConfig.Etcd.CompactionInterval == "" {
+		cluster.ComponentConfig.Etcd.CompactionInterval = c.KubeOneHosts.Value.EtcdCompactionInterval
+	}
+
+	// Pod Security Admission is not available in the supported Kubernetes
+	// versions, the standards are approximated using PodSecurityPolicies,
+	// which only allow privileged pods in kube-system when enabled. Only
+	// set it if it was not configured yet to ensure config from
+	// `config.yaml` takes precedence
+	switch c.KubeOneHosts.Value.PodSecurityStandard {
+	case "":
+	case "privileged", "baseline", "restricted":
+		if cluster.Features.PodSecurityPolicy == nil {
+			cluster.Features.PodSecurityPolicy = &kubeonev1alpha1.PodSecurityPolicy{
+				Enable: c.KubeOneHosts.Value.PodSecurityStandard != "privileged",
+			}
+		}
+	default:
+		return errors.Errorf("invalid pod_security_standard %q, must be one of privileged, baseline or restricted", c.KubeOneHosts.Value.PodSecurityStandard)
+	}
+
+	// Only enable OpenID Connect if it was not configured yet to ensure config
+	// from `config.yaml` takes precedence
+	if c.KubeOneHosts.Value.OIDCIssuerURL != "" && cluster.Features.OpenIDConnect == nil {
+		cluster.Features.OpenIDConnect = &kubeonev1alpha1.OpenIDConnect{
+			Enable: true,
+			Config: kubeonev1alpha1.OpenIDConnectConfig{
+				IssuerURL:   c.KubeOneHosts.Value.OIDCIssuerURL,
+				ClientID:    c.KubeOneHosts.Value.OIDCClientID,
+				CAFile:      c.KubeOneHosts.Value.OIDCCAFile,
+				GroupsClaim: c.KubeOneHosts.Value.OIDCGroupsClaim,
+			},
+		}
+	}
+
+	if c.KubeOneHosts.Value.ExternalCloudProvider != nil {
+		cluster.CloudProvider.External = *c.KubeOneHosts.Value.ExternalCloudProvider
+	}
+
+	// Only pin the CCM version if it was not configured yet to ensure config
+	// from `config.yaml` takes precedence
+	if c.KubeOneHosts.Value.CCMVersion != "" && cluster.CloudProvider.CCMVersion == "" {
+		cluster.CloudProvider.CCMVersion = c.KubeOneHosts.Value.CCMVersion
 	}
 
+	return nil
+}
+
+func (c *Config) applyHosts(cluster *kubeonev1alpha1.KubeOneCluster) error {
 	cp := c.KubeOneHosts.Value.ControlPlane[0]
 
 	if cp.CloudProvider != nil {
 		cluster.CloudProvider.Name = kubeonev1alpha1.CloudProviderName(*cp.CloudProvider)
 	}
 
-	var sshPort int
-	var err error
-	if cp.SSHPort != "" {
-		sshPort, err = strconv.Atoi(cp.SSHPort)
-		if err != nil {
-			return errors.Wrapf(err, "failed to convert ssh port string %q to int", cp.SSHPort)
-		}
+	switch cluster.CloudProvider.Name {
+	case kubeonev1alpha1.CloudProviderNameOpenStack:
+		cluster.CloudProvider.CloudConfig = c.KubeOneHosts.Value.OpenStack.updateCloudConfig(cluster.CloudProvider.CloudConfig)
+	case kubeonev1alpha1.CloudProviderNameGCE:
+		cluster.CloudProvider.CloudConfig = c.KubeOneHosts.Value.GCE.updateCloudConfig(cluster.CloudProvider.CloudConfig)
 	}
 
 	cluster.Name = cp.ClusterName
 
-	// build up a list of master nodes
-	hosts := make([]kubeonev1alpha1.HostConfig, 0)
-	for i, publicIP := range cp.PublicAddress {
-		privateIP := publicIP
-		if i < len(cp.PrivateAddress) {
-			privateIP = cp.PrivateAddress[i]
-		}
-
-		hosts = append(hosts, kubeonev1alpha1.HostConfig{
-			ID:                i,
-			PublicAddress:     publicIP,
-			PrivateAddress:    privateIP,
-			SSHUsername:       cp.SSHUser,
-			SSHPort:           sshPort,
-			SSHPrivateKeyFile: cp.SSHPrivateKeyFile,
-			SSHAgentSocket:    cp.SSHAgentSocket,
-		})
+	// A control plane without any addresses only provides the cluster
+	// config, the hosts are kept as given in `config.yaml`
+	if c.ControlPlaneCount() == 0 {
+		return nil
+	}
+
+	hosts, err := c.ControlPlaneHostConfigs()
+	if err != nil {
+		return ApplyErrors{err}
 	}
+	cluster.Hosts = hosts
 
-	if len(hosts) > 0 {
-		cluster.Hosts = hosts
+	return nil
+}
+
+func (c *Config) applyWorkers(cluster *kubeonev1alpha1.KubeOneCluster) error {
+	c.applyMachineController(cluster)
+	return c.applyWorkersets(cluster, c.WorkerSetNames(), false)
+}
+
+func (c *Config) applyMachineController(cluster *kubeonev1alpha1.KubeOneCluster) {
+	// Only disable the machine-controller if it was not configured yet to
+	// ensure config from `config.yaml` takes precedence
+	if enabled := c.KubeOneHosts.Value.MachineControllerEnabled; enabled != nil && cluster.MachineController == nil {
+		cluster.MachineController = &kubeonev1alpha1.MachineControllerConfig{Deploy: *enabled}
+	}
+
+	// Only pin the machine-controller version if it was not configured yet to
+	// ensure config from `config.yaml` takes precedence
+	if v := c.KubeOneHosts.Value.MachineControllerVersion; v != "" {
+		if cluster.MachineController == nil {
+			cluster.MachineController = &kubeonev1alpha1.MachineControllerConfig{Deploy: true}
+		}
+		if cluster.MachineController.Version == "" {
+			cluster.MachineController.Version = v
+		}
+	}
+}
+
+// applyWorkersets applies the workersets with the given names. If concurrent
+// is true, the workersets are updated in parallel by at most one goroutine
+// per CPU.
+func (c *Config) applyWorkersets(cluster *kubeonev1alpha1.KubeOneCluster, names []string, concurrent bool) error {
+	provider := c.cloudProviderName(cluster)
+
+	var errs ApplyErrors
+
+	type workersetUpdate struct {
+		name  string
+		value json.RawMessage
+		index int
 	}
+	var updates []workersetUpdate
 
-	// Walk through all configued workersets from terraform and apply their config
-	// by either merging it into an existing workerSet or creating a new one
-	for workersetName, workersetValue := range c.KubeOneWorkers.Value {
-		if len(workersetValue) != 1 {
+	// Walk through all configued workersets from terraform and find the
+	// workerSet to merge their config into, or create a new one
+	for _, workersetName := range names {
+		workersetValue, ok := c.WorkerSetByName(workersetName)
+		if !ok {
 			// TODO: log warning? error?
 			continue
 		}
 
-		var existingWorkerSet *kubeonev1alpha1.WorkerConfig
+		// workerset names are used as MachineDeployment names
+		if msgs := validation.IsDNS1123Subdomain(workersetName); len(msgs) > 0 {
+			errs = append(errs, errors.Errorf("invalid workerset name %q: %s", workersetName, strings.Join(msgs, ", ")))
+			continue
+		}
+
+		index := -1
 		for idx, workerset := range cluster.Workers {
 			if workerset.Name == workersetName {
-				existingWorkerSet = &cluster.Workers[idx]
+				index = idx
 				break
 			}
 		}
-		if existingWorkerSet == nil {
-			// Append copies the object when its a literal and not a pointer, hence
-			// we have to first append, then create a pointer to the appended object
+		if index < 0 {
 			cluster.Workers = append(cluster.Workers, kubeonev1alpha1.WorkerConfig{Name: workersetName})
-			existingWorkerSet = &cluster.Workers[len(cluster.Workers)-1]
-		}
-
-		switch cluster.CloudProvider.Name {
-		case kubeonev1alpha1.CloudProviderNameAWS:
-			err = c.updateAWSWorkerset(existingWorkerSet, workersetValue[0])
-		case kubeonev1alpha1.CloudProviderNameAzure:
-			err = c.updateAzureWorkerset(existingWorkerSet, workersetValue[0])
-		case kubeonev1alpha1.CloudProviderNameGCE:
-			err = c.updateGCEWorkerset(existingWorkerSet, workersetValue[0])
-		case kubeonev1alpha1.CloudProviderNameDigitalOcean:
-			err = c.updateDigitalOceanWorkerset(existingWorkerSet, workersetValue[0])
-		case kubeonev1alpha1.CloudProviderNameHetzner:
-			err = c.updateHetznerWorkerset(existingWorkerSet, workersetValue[0])
-		case kubeonev1alpha1.CloudProviderNameOpenStack:
-			err = c.updateOpenStackWorkerset(existingWorkerSet, workersetValue[0])
-		case kubeonev1alpha1.CloudProviderNameVSphere:
-			err = c.updateVSphereWorkerset(existingWorkerSet, workersetValue[0])
-		case kubeonev1alpha1.CloudProviderNamePacket:
-			err = c.updatePacketWorkerset(existingWorkerSet, workersetValue[0])
-		default:
-			return errors.Errorf("unknown provider %v", cluster.CloudProvider.Name)
+			index = len(cluster.Workers) - 1
 		}
 
-		if err != nil {
-			return errors.Wrapf(err, "failed to update provider-specific config for workerset %q from terraform config", workersetName)
+		updates = append(updates, workersetUpdate{name: workersetName, value: json.RawMessage(workersetValue), index: index})
+	}
+
+	if len(updates) > 0 && c.providerWorkersetUpdater(provider) == nil {
+		return errors.Errorf("unknown provider %v", provider)
+	}
+
+	// Every update only touches its own workerSet and collects its errors
+	// and warnings separately, so they are reported in the same order
+	// regardless of concurrency
+	updateErrs := make([]ApplyErrors, len(updates))
+	updateWarnings := make([][]string, len(updates))
+	update := func(i int) {
+		u := updates[i]
+		scratch := *c
+		scratch.warnings = nil
+		// the workerSets are not appended to anymore, so the pointer is stable
+		workerset := &cluster.Workers[u.index]
+
+		if err := scratch.providerWorkersetUpdater(provider)(workerset, u.value); err != nil {
+			updateErrs[i] = append(updateErrs[i], errors.Wrapf(err, "failed to update provider-specific config for workerset %q from terraform config", u.name))
 		}
 
 		// copy over common config
-		if err = c.updateCommonWorkerConfig(existingWorkerSet, workersetValue[0]); err != nil {
-			return errors.Wrap(err, "failed to update common config from terraform config")
+		if err := scratch.updateCommonWorkerConfig(workerset, u.value); err != nil {
+			updateErrs[i] = append(updateErrs[i], errors.Wrapf(err, "failed to update common config for workerset %q from terraform config", u.name))
+		}
+
+		updateWarnings[i] = scratch.warnings
+	}
+
+	// the updates are CPU-bound, so there is no point in running more of them
+	// in parallel than there are CPUs
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(updates) {
+		workers = len(updates)
+	}
+
+	if concurrent && workers > 1 {
+		indexes := make(chan int)
+		wg := sync.WaitGroup{}
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range indexes {
+					update(i)
+				}
+			}()
 		}
+		for i := range updates {
+			indexes <- i
+		}
+		close(indexes)
+		wg.Wait()
+	} else {
+		for i := range updates {
+			update(i)
+		}
+	}
+
+	for i := range updates {
+		errs = append(errs, updateErrs[i]...)
+		c.warnings = append(c.warnings, updateWarnings[i]...)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// cloudProviderName returns the cloud provider the workersets are updated
+// for. Like in Apply, the cloud provider from the terraform output takes
+// precedence over the one from the given cluster config.
+func (c *Config) cloudProviderName(cluster *kubeonev1alpha1.KubeOneCluster) kubeonev1alpha1.CloudProviderName {
+	if c.HasControlPlane() && c.KubeOneHosts.Value.ControlPlane[0].CloudProvider != nil {
+		return kubeonev1alpha1.CloudProviderName(*c.KubeOneHosts.Value.ControlPlane[0].CloudProvider)
+	}
+
+	return cluster.CloudProvider.Name
+}
+
+// providerWorkersetUpdater returns the function updating the provider-specific
+// config of a workerset, or nil if the provider is not supported
+func (c *Config) providerWorkersetUpdater(provider kubeonev1alpha1.CloudProviderName) func(*kubeonev1alpha1.WorkerConfig, json.RawMessage) error {
+	switch provider {
+	case kubeonev1alpha1.CloudProviderNameAWS:
+		return c.updateAWSWorkerset
+	case kubeonev1alpha1.CloudProviderNameAzure:
+		return c.updateAzureWorkerset
+	case kubeonev1alpha1.CloudProviderNameGCE:
+		return c.updateGCEWorkerset
+	case kubeonev1alpha1.CloudProviderNameDigitalOcean:
+		return c.updateDigitalOceanWorkerset
+	case kubeonev1alpha1.CloudProviderNameHetzner:
+		return c.updateHetznerWorkerset
+	case kubeonev1alpha1.CloudProviderNameOpenStack:
+		return c.updateOpenStackWorkerset
+	case kubeonev1alpha1.CloudProviderNameVSphere:
+		return c.updateVSphereWorkerset
+	case kubeonev1alpha1.CloudProviderNamePacket:
+		return c.updatePacketWorkerset
 	}
 
 	return nil
 }
 
+// updateCloudConfig adds the project ID to the given GCE cloud config, unless
+// it already has its own global section
+func (g gceConfig) updateCloudConfig(cloudConfig string) string {
+	if g.ProjectID == "" || strings.Contains(cloudConfig, "[global]") {
+		return cloudConfig
+	}
+
+	return fmt.Sprintf("[global]\nproject-id = %q\n", g.ProjectID) + cloudConfig
+}
+
+// updateCloudConfig appends the load balancer options to the given OpenStack
+// cloud config, unless it already has its own LoadBalancer section
+func (o openStackConfig) updateCloudConfig(cloudConfig string) string {
+	if o.UseOctavia == nil && o.LBFloatingNetworkID == "" {
+		return cloudConfig
+	}
+	if strings.Contains(cloudConfig, "[LoadBalancer]") {
+		return cloudConfig
+	}
+
+	var lbConfig strings.Builder
+	if cloudConfig != "" && !strings.HasSuffix(cloudConfig, "\n") {
+		lbConfig.WriteString("\n")
+	}
+	lbConfig.WriteString("[LoadBalancer]\n")
+	if o.UseOctavia != nil {
+		fmt.Fprintf(&lbConfig, "use-octavia = %t\n", *o.UseOctavia)
+	}
+	if o.LBFloatingNetworkID != "" {
+		fmt.Fprintf(&lbConfig, "floating-network-id = %q\n", o.LBFloatingNetworkID)
+	}
+
+	return cloudConfig + lbConfig.String()
+}
+
+// setDefaultExtraArgs adds the given extra args to the destination, unless
+// they are already set there
+func setDefaultExtraArgs(dst *map[string]string, args map[string]string) {
+	if len(args) == 0 {
+		return
+	}
+	if *dst == nil {
+		*dst = map[string]string{}
+	}
+
+	for k, v := range args {
+		if _, exists := (*dst)[k]; !exists {
+			(*dst)[k] = v
+		}
+	}
+}
+
+// awsWorkerConfig is the AWS workerset config in the terraform output, which
+// can give security groups to be appended instead of replacing them
+type awsWorkerConfig struct {
+	machinecontroller.AWSSpec
+	AdditionalSecurityGroupIDs []string `json:"additionalSecurityGroupIDs"`
+}
+
 func (c *Config) updateAWSWorkerset(workerset *kubeonev1alpha1.WorkerConfig, cfg json.RawMessage) error {
-	var awsCloudConfig machinecontroller.AWSSpec
+	var awsCloudConfig awsWorkerConfig
 
 	if err := json.Unmarshal(cfg, &awsCloudConfig); err != nil {
 		return errors.WithStack(err)
 	}
 
+	if awsCloudConfig.SubnetID != "" && len(awsCloudConfig.SubnetIDs) > 0 {
+		return errors.New("only one of subnetId and subnetIds can be given")
+	}
+
+	// A single subnet is always set as subnetId, the subnetIds list is only
+	// used for workersets spanning multiple subnets
+	subnetID := awsCloudConfig.SubnetID
+	var subnetIDs []string
+	switch {
+	case len(awsCloudConfig.SubnetIDs) == 1:
+		subnetID = awsCloudConfig.SubnetIDs[0]
+	case len(awsCloudConfig.SubnetIDs) > 1:
+		subnetIDs = awsCloudConfig.SubnetIDs
+	}
+
 	flags := []cloudProviderFlags{
 		{key: "ami", value: awsCloudConfig.AMI},
 		{key: "availabilityZone", value: awsCloudConfig.AvailabilityZone},
 		{key: "instanceProfile", value: awsCloudConfig.InstanceProfile},
 		{key: "region", value: awsCloudConfig.Region},
 		{key: "securityGroupIDs", value: awsCloudConfig.SecurityGroupIDs},
-		{key: "subnetId", value: awsCloudConfig.SubnetID},
+		{key: "subnetId", value: subnetID},
+		{key: "subnetIds", value: subnetIDs},
 		{key: "vpcId", value: awsCloudConfig.VPCID},
 		{key: "instanceType", value: awsCloudConfig.InstanceType},
 		{key: "tags", value: awsCloudConfig.Tags},
+		{key: "launchTemplateID", value: awsCloudConfig.LaunchTemplateID},
+		{key: "launchTemplateVersion", value: awsCloudConfig.LaunchTemplateVersion},
+		{key: "disableSrcDstCheck", value: awsCloudConfig.DisableSrcDstCheck},
+		{key: "metadataOptions", value: awsCloudConfig.MetadataOptions},
+		{key: "diskKMSKeyID", value: awsCloudConfig.DiskKMSKeyID},
+		{key: "capacityReservationID", value: awsCloudConfig.CapacityReservationID},
+		{key: "capacityReservationPreference", value: awsCloudConfig.CapacityReservationPreference},
+		{key: "networkInterfaces", value: awsCloudConfig.NetworkInterfaces},
 	}
 
 	for _, flag := range flags {
@@ -217,6 +1468,10 @@ func (c *Config) updateAWSWorkerset(workerset *kubeonev1alpha1.WorkerConfig, cfg
 		}
 	}
 
+	if err := appendWorkersetListFlag(workerset, "securityGroupIDs", awsCloudConfig.AdditionalSecurityGroupIDs); err != nil {
+		return errors.WithStack(err)
+	}
+
 	return nil
 }
 
@@ -238,6 +1493,9 @@ func (c *Config) updateAzureWorkerset(workerset *kubeonev1alpha1.WorkerConfig, c
 		{key: "tags", value: azureCloudConfig.Tags},
 		{key: "vmSize", value: azureCloudConfig.VMSize},
 		{key: "vnetName", value: azureCloudConfig.VNetName},
+		{key: "ultraDiskEnabled", value: azureCloudConfig.UltraDiskEnabled},
+		{key: "diskControllerType", value: azureCloudConfig.DiskControllerType},
+		{key: "acceleratedNetworking", value: azureCloudConfig.AcceleratedNetworking},
 	}
 
 	for _, flag := range flags {
@@ -249,11 +1507,27 @@ func (c *Config) updateAzureWorkerset(workerset *kubeonev1alpha1.WorkerConfig, c
 	return nil
 }
 
+// gceWorkerConfig is the GCE workerset config in the terraform output, which
+// has a flat KMS key name instead of machine-controller's disk encryption key
+type gceWorkerConfig struct {
+	machinecontroller.GCESpec
+	DiskEncryptionKMSKey string `json:"diskEncryptionKMSKey"`
+}
+
 func (c *Config) updateGCEWorkerset(workerset *kubeonev1alpha1.WorkerConfig, cfg json.RawMessage) error {
-	var gceCloudConfig machinecontroller.GCESpec
+	var gceCloudConfig gceWorkerConfig
 
 	if err := json.Unmarshal(cfg, &gceCloudConfig); err != nil {
-		return errors.WithStack(err)
+		// labels and tags are easily mixed up, as both are called tags by
+		// other providers
+		return errors.Wrap(err, "failed to parse GCE workerset, labels must be a map of strings and tags a list of network tags")
+	}
+
+	// machine-controller expects the KMS key name nested in the disk
+	// encryption key
+	var diskEncryptionKey *machinecontroller.GCEDiskEncryptionKey
+	if gceCloudConfig.DiskEncryptionKMSKey != "" {
+		diskEncryptionKey = &machinecontroller.GCEDiskEncryptionKey{KMSKeyName: gceCloudConfig.DiskEncryptionKMSKey}
 	}
 
 	flags := []cloudProviderFlags{
@@ -269,6 +1543,9 @@ func (c *Config) updateGCEWorkerset(workerset *kubeonev1alpha1.WorkerConfig, cfg
 		{key: "tags", value: gceCloudConfig.Tags},
 		{key: "multizone", value: gceCloudConfig.MultiZone},
 		{key: "regional", value: gceCloudConfig.Regional},
+		{key: "confidentialComputing", value: gceCloudConfig.ConfidentialComputing},
+		{key: "minCpuPlatform", value: gceCloudConfig.MinCPUPlatform},
+		{key: "diskEncryptionKey", value: diskEncryptionKey},
 	}
 
 	for _, flag := range flags {
@@ -295,6 +1572,7 @@ func (c *Config) updateDigitalOceanWorkerset(workerset *kubeonev1alpha1.WorkerCo
 		{key: "private_networking", value: doCloudConfig.PrivateNetworking},
 		{key: "monitoring", value: doCloudConfig.Monitoring},
 		{key: "tags", value: doCloudConfig.Tags},
+		{key: "sshKeyFingerprints", value: doCloudConfig.SSHKeyFingerprints},
 	}
 
 	for _, flag := range flags {
@@ -317,6 +1595,8 @@ func (c *Config) updateHetznerWorkerset(workerset *kubeonev1alpha1.WorkerConfig,
 		{key: "serverType", value: hetznerConfig.ServerType},
 		{key: "datacenter", value: hetznerConfig.Datacenter},
 		{key: "location", value: hetznerConfig.Location},
+		{key: "image", value: hetznerConfig.Image},
+		{key: "sshKeys", value: hetznerConfig.SSHKeys},
 	}
 
 	for _, flag := range flags {
@@ -344,6 +1624,7 @@ func (c *Config) updateOpenStackWorkerset(workerset *kubeonev1alpha1.WorkerConfi
 		{key: "network", value: openstackConfig.Network},
 		{key: "subnet", value: openstackConfig.Subnet},
 		{key: "tags", value: openstackConfig.Tags},
+		{key: "serverGroupID", value: openstackConfig.ServerGroupID},
 	}
 
 	for _, flag := range flags {
@@ -362,10 +1643,18 @@ func (c *Config) updatePacketWorkerset(workerset *kubeonev1alpha1.WorkerConfig,
 		return err
 	}
 
+	// user data is passed base64 encoded, so it survives the terraform output
+	// unchanged
+	userData, err := base64.StdEncoding.DecodeString(packetConfig.UserData)
+	if err != nil {
+		return errors.Wrap(err, "failed to decode base64 encoded userData")
+	}
+
 	flags := []cloudProviderFlags{
 		{key: "projectID", value: packetConfig.ProjectID},
 		{key: "facilities", value: packetConfig.Facilities},
 		{key: "instanceType", value: packetConfig.InstanceType},
+		{key: "userData", value: string(userData)},
 	}
 
 	for _, flag := range flags {
@@ -384,17 +1673,29 @@ func (c *Config) updateVSphereWorkerset(workerset *kubeonev1alpha1.WorkerConfig,
 		return err
 	}
 
+	// a template name with slashes is a full inventory path, which avoids
+	// ambiguous names with multiple datacenters
+	if strings.Contains(vsphereConfig.TemplateVMName, "/") {
+		if vsphereConfig.TemplateVMInventoryPath == "" {
+			vsphereConfig.TemplateVMInventoryPath = vsphereConfig.TemplateVMName
+		}
+		vsphereConfig.TemplateVMName = path.Base(vsphereConfig.TemplateVMName)
+	}
+
 	flags := []cloudProviderFlags{
 		{key: "allowInsecure", value: vsphereConfig.AllowInsecure},
 		{key: "cluster", value: vsphereConfig.Cluster},
 		{key: "cpus", value: vsphereConfig.CPUs},
+		{key: "customAttributes", value: vsphereConfig.CustomAttributes},
 		{key: "datacenter", value: vsphereConfig.Datacenter},
 		{key: "datastore", value: vsphereConfig.Datastore},
 		{key: "diskSizeGB", value: vsphereConfig.DiskSizeGB},
 		{key: "folder", value: vsphereConfig.Folder},
 		{key: "memoryMB", value: vsphereConfig.MemoryMB},
+		{key: "resourcePool", value: vsphereConfig.ResourcePoolPath},
 		{key: "templateNetName", value: vsphereConfig.TemplateNetName},
 		{key: "templateVMName", value: vsphereConfig.TemplateVMName},
+		{key: "templateVMInventoryPath", value: vsphereConfig.TemplateVMInventoryPath},
 		{key: "vmNetName", value: vsphereConfig.VMNetName},
 	}
 
@@ -407,6 +1708,9 @@ func (c *Config) updateVSphereWorkerset(workerset *kubeonev1alpha1.WorkerConfig,
 	return nil
 }
 
+// setWorkersetFlag sets the given value in CloudProviderSpec, unless it is
+// empty or already set. Existing values are never overwritten, as they come
+// from `config.yaml`, which takes precedence over the terraform output.
 func setWorkersetFlag(w *kubeonev1alpha1.WorkerConfig, name string, value interface{}) error {
 	// ignore empty values (i.e. not set in terraform output)
 	switch s := value.(type) {
@@ -440,7 +1744,16 @@ func setWorkersetFlag(w *kubeonev1alpha1.WorkerConfig, name string, value interf
 			return nil
 		}
 	default:
-		return errors.New("unsupported type")
+		// any other type, such as structs or slices of structs, is stored as
+		// its JSON representation
+		raw, err := json.Marshal(s)
+		if err != nil {
+			return errors.Wrapf(err, "unable to marshal the value of %q", name)
+		}
+		if string(raw) == "null" {
+			return nil
+		}
+		value = json.RawMessage(raw)
 	}
 
 	// update CloudProviderSpec ONLY IF given terraform output is absent in
@@ -465,24 +1778,94 @@ func setWorkersetFlag(w *kubeonev1alpha1.WorkerConfig, name string, value interf
 	return nil
 }
 
+// appendWorkersetListFlag appends the given values to the list in
+// CloudProviderSpec, skipping values that are already in there
+func appendWorkersetListFlag(w *kubeonev1alpha1.WorkerConfig, name string, values []string) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	jsonSpec := make(map[string]interface{})
+	if w.Config.CloudProviderSpec != nil {
+		if err := json.Unmarshal(w.Config.CloudProviderSpec, &jsonSpec); err != nil {
+			return errors.Wrap(err, "unable to parse the provided cloud provider")
+		}
+	}
+
+	var list []interface{}
+	if existing, ok := jsonSpec[name]; ok && existing != nil {
+		if list, ok = existing.([]interface{}); !ok {
+			return errors.Errorf("%q in the cloud provider spec is not a list", name)
+		}
+	}
+	for _, value := range values {
+		found := false
+		for _, item := range list {
+			if item == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, value)
+		}
+	}
+	jsonSpec[name] = list
+
+	var err error
+	w.Config.CloudProviderSpec, err = json.Marshal(jsonSpec)
+	if err != nil {
+		return errors.Wrap(err, "unable to update the cloud provider spec")
+	}
+
+	return nil
+}
+
 type commonWorkerConfig struct {
 	SSHPublicKeys       []string              `json:"sshPublicKeys"`
 	Replicas            *int                  `json:"replicas"`
+	MaxSurge            *intstr.IntOrString   `json:"maxSurge"`
+	MaxUnavailable      *intstr.IntOrString   `json:"maxUnavailable"`
 	OperatingSystem     *string               `json:"operatingSystem"`
 	OperatingSystemSpec []operatingSystemSpec `json:"operatingSystemSpec"`
+	DiskEncryptionKeyID string                `json:"diskEncryptionKeyID"`
 }
 
 type operatingSystemSpec struct {
-	DistUpgradeOnBoot *bool `json:"distUpgradeOnBoot"`
+	DistUpgradeOnBoot *bool    `json:"distUpgradeOnBoot"`
+	KernelModules     []string `json:"kernelModules"`
+
+	// Flatcar Container Linux is provisioned using Ignition instead of
+	// cloud-init, hence it has its own set of options
+	Version string          `json:"version"`
+	Storage json.RawMessage `json:"storage"`
+	Systemd json.RawMessage `json:"systemd"`
 }
 
+const operatingSystemFlatcar = "flatcar"
+
 func (c *Config) updateCommonWorkerConfig(workerset *kubeonev1alpha1.WorkerConfig, cfg json.RawMessage) error {
 	var cc commonWorkerConfig
 	if err := json.Unmarshal(cfg, &cc); err != nil {
 		return errors.Wrap(err, "failed to unmarshal common worker config")
 	}
 
-	for _, sshKey := range cc.SSHPublicKeys {
+	// keys are identified by their type and data, ignoring the comment
+	knownKeys := map[string]bool{}
+	for _, sshKey := range workerset.Config.SSHPublicKeys {
+		if pubKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(sshKey)); err == nil {
+			knownKeys[string(pubKey.Marshal())] = true
+		}
+	}
+	for i, sshKey := range cc.SSHPublicKeys {
+		pubKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(sshKey))
+		if err != nil {
+			return errors.Wrapf(err, "invalid ssh public key %d", i)
+		}
+		if knownKeys[string(pubKey.Marshal())] {
+			continue
+		}
+		knownKeys[string(pubKey.Marshal())] = true
 		workerset.Config.SSHPublicKeys = append(workerset.Config.SSHPublicKeys, sshKey)
 	}
 
@@ -492,16 +1875,74 @@ func (c *Config) updateCommonWorkerConfig(workerset *kubeonev1alpha1.WorkerConfi
 		workerset.Replicas = cc.Replicas
 	}
 
+	// The same applies to the rolling update strategy
+	if cc.MaxSurge != nil || cc.MaxUnavailable != nil {
+		if workerset.RollingUpdate == nil {
+			workerset.RollingUpdate = &kubeonev1alpha1.RollingUpdateConfig{}
+		}
+		if cc.MaxSurge != nil && workerset.RollingUpdate.MaxSurge == nil {
+			workerset.RollingUpdate.MaxSurge = cc.MaxSurge
+		}
+		if cc.MaxUnavailable != nil && workerset.RollingUpdate.MaxUnavailable == nil {
+			workerset.RollingUpdate.MaxUnavailable = cc.MaxUnavailable
+		}
+	}
+
+	// The same applies to the disk encryption key
+	if cc.DiskEncryptionKeyID != "" && workerset.Config.DiskEncryptionKeyID == "" {
+		workerset.Config.DiskEncryptionKeyID = cc.DiskEncryptionKeyID
+	}
+
 	// Overwrite config from `config.yaml` as the info about the image/AMI/Whatever your cloud calls it
 	// comes from Terraform
 	if cc.OperatingSystem != nil {
 		workerset.Config.OperatingSystem = *cc.OperatingSystem
 	}
 
+	// Merge all operatingSystemSpec entries, later entries take precedence
+	// over earlier ones
 	osSpecMap := make(map[string]interface{})
+	setOSSpec := func(key string, value interface{}) {
+		if prev, ok := osSpecMap[key]; ok && !reflect.DeepEqual(prev, value) {
+			c.warnings = append(c.warnings, fmt.Sprintf("workerset %q: conflicting operatingSystemSpec values for %q, using the last one", workerset.Name, key))
+		}
+		osSpecMap[key] = value
+	}
+	var kernelModules []string
 	for _, v := range cc.OperatingSystemSpec {
-		if v.DistUpgradeOnBoot != nil {
-			osSpecMap["distUpgradeOnBoot"] = *v.DistUpgradeOnBoot
+		if workerset.Config.OperatingSystem == operatingSystemFlatcar {
+			if v.Version != "" {
+				setOSSpec("version", v.Version)
+			}
+			if v.Storage != nil {
+				setOSSpec("storage", v.Storage)
+			}
+			if v.Systemd != nil {
+				setOSSpec("systemd", v.Systemd)
+			}
+		} else if v.DistUpgradeOnBoot != nil {
+			setOSSpec("distUpgradeOnBoot", *v.DistUpgradeOnBoot)
+		}
+		for _, module := range v.KernelModules {
+			if !containsString(kernelModules, module) {
+				kernelModules = append(kernelModules, module)
+			}
+		}
+	}
+	if len(kernelModules) > 0 {
+		osSpecMap["kernelModules"] = kernelModules
+	}
+
+	// Keep the fields from `config.yaml` which are not set by Terraform
+	if len(osSpecMap) > 0 && len(workerset.Config.OperatingSystemSpec) > 0 {
+		existing := make(map[string]interface{})
+		if err := json.Unmarshal(workerset.Config.OperatingSystemSpec, &existing); err != nil {
+			return errors.Wrap(err, "unable to parse the operating system spec")
+		}
+		for k, v := range existing {
+			if _, ok := osSpecMap[k]; !ok {
+				osSpecMap[k] = v
+			}
 		}
 	}
 
@@ -515,3 +1956,12 @@ func (c *Config) updateCommonWorkerConfig(workerset *kubeonev1alpha1.WorkerConfi
 
 	return nil
 }
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,2244 @@
+/*
+Copyright 2019 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terraform
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	kubeonev1alpha1 "github.com/kubermatic/kubeone/pkg/apis/kubeone/v1alpha1"
+)
+
+const testOutput = `{
+  "kubeone_api": {
+    "value": {
+      "endpoint": "lb.example.com"
+    }
+  },
+  "kubeone_hosts": {
+    "value": {
+      "control_plane": [
+        {
+          "cluster_name": "test",
+          "cloud_provider": "aws",
+          "public_address": ["1.1.1.1", "1.1.1.2", "1.1.1.3"],
+          "private_address": ["10.0.0.1", "10.0.0.2"],
+          "ssh_user": "ubuntu",
+          "ssh_port": "2222",
+          "ssh_agent_socket": "env:SSH_AUTH_SOCK",
+          "node_labels": {"topology.kubernetes.io/region": "eu-central-1"},
+          "node_taints": ["node-role.kubernetes.io/master:NoSchedule"],
+          "kubelet_extra_config": {"max-pods": "50"}
+        }
+      ]
+    }
+  }
+}`
+
+func TestControlPlaneHostConfig(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewConfigFromJSON([]byte(testOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		name         string
+		idx          int
+		expectedHost *kubeonev1alpha1.HostConfig
+		expectedErr  bool
+	}{
+		{
+			name: "first host",
+			idx:  0,
+			expectedHost: &kubeonev1alpha1.HostConfig{
+				ID:               0,
+				PublicAddress:    "1.1.1.1",
+				PrivateAddress:   "10.0.0.1",
+				SSHUsername:      "ubuntu",
+				SSHPort:          2222,
+				SSHAgentSocket:   "env:SSH_AUTH_SOCK",
+				Labels:           map[string]string{"topology.kubernetes.io/region": "eu-central-1"},
+				Taints:           []corev1.Taint{{Key: "node-role.kubernetes.io/master", Effect: corev1.TaintEffectNoSchedule}},
+				KubeletExtraArgs: map[string]string{"max-pods": "50"},
+			},
+		},
+		{
+			name: "host without private address",
+			idx:  2,
+			expectedHost: &kubeonev1alpha1.HostConfig{
+				ID:               2,
+				PublicAddress:    "1.1.1.3",
+				PrivateAddress:   "1.1.1.3",
+				SSHUsername:      "ubuntu",
+				SSHPort:          2222,
+				SSHAgentSocket:   "env:SSH_AUTH_SOCK",
+				Labels:           map[string]string{"topology.kubernetes.io/region": "eu-central-1"},
+				Taints:           []corev1.Taint{{Key: "node-role.kubernetes.io/master", Effect: corev1.TaintEffectNoSchedule}},
+				KubeletExtraArgs: map[string]string{"max-pods": "50"},
+			},
+		},
+		{
+			name:        "negative index",
+			idx:         -1,
+			expectedErr: true,
+		},
+		{
+			name:        "index out of range",
+			idx:         3,
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			host, err := c.ControlPlaneHostConfig(tc.idx)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error %t, but got %v", tc.expectedErr, err)
+			}
+			if !reflect.DeepEqual(host, tc.expectedHost) {
+				t.Fatalf("expected host %+v, but got %+v", tc.expectedHost, host)
+			}
+		})
+	}
+}
+
+func TestParseTaint(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name          string
+		taint         string
+		expectedTaint corev1.Taint
+		expectedErr   bool
+	}{
+		{
+			name:          "key and effect",
+			taint:         "dedicated:NoSchedule",
+			expectedTaint: corev1.Taint{Key: "dedicated", Effect: corev1.TaintEffectNoSchedule},
+		},
+		{
+			name:          "key, value and effect",
+			taint:         "dedicated=etcd:NoExecute",
+			expectedTaint: corev1.Taint{Key: "dedicated", Value: "etcd", Effect: corev1.TaintEffectNoExecute},
+		},
+		{
+			name:        "missing effect",
+			taint:       "dedicated=etcd",
+			expectedErr: true,
+		},
+		{
+			name:        "unsupported effect",
+			taint:       "dedicated=etcd:Never",
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			taint, err := parseTaint(tc.taint)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error %t, but got %v", tc.expectedErr, err)
+			}
+			if !tc.expectedErr && !reflect.DeepEqual(taint, tc.expectedTaint) {
+				t.Fatalf("expected taint %+v, but got %+v", tc.expectedTaint, taint)
+			}
+		})
+	}
+}
+
+func TestSetWorkersetFlag(t *testing.T) {
+	t.Parallel()
+
+	type accelerator struct {
+		Type  string `json:"type"`
+		Count int    `json:"count"`
+	}
+
+	testcases := []struct {
+		name         string
+		existingSpec string
+		key          string
+		value        interface{}
+		expectedSpec string
+	}{
+		{
+			name:         "string",
+			key:          "region",
+			value:        "eu-central-1",
+			expectedSpec: `{"region":"eu-central-1"}`,
+		},
+		{
+			name:         "empty string is ignored",
+			existingSpec: `{}`,
+			key:          "region",
+			value:        "",
+			expectedSpec: `{}`,
+		},
+		{
+			name:         "existing value is kept",
+			existingSpec: `{"region":"us-east-1"}`,
+			key:          "region",
+			value:        "eu-central-1",
+			expectedSpec: `{"region":"us-east-1"}`,
+		},
+		{
+			name:         "slice of structs",
+			key:          "accelerators",
+			value:        []accelerator{{Type: "nvidia-tesla-k80", Count: 1}},
+			expectedSpec: `{"accelerators":[{"type":"nvidia-tesla-k80","count":1}]}`,
+		},
+		{
+			name:         "nil slice of structs is ignored",
+			existingSpec: `{}`,
+			key:          "accelerators",
+			value:        []accelerator(nil),
+			expectedSpec: `{}`,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			w := &kubeonev1alpha1.WorkerConfig{}
+			if tc.existingSpec != "" {
+				w.Config.CloudProviderSpec = []byte(tc.existingSpec)
+			}
+			if err := setWorkersetFlag(w, tc.key, tc.value); err != nil {
+				t.Fatal(err)
+			}
+			if string(w.Config.CloudProviderSpec) != tc.expectedSpec {
+				t.Fatalf("expected spec %s, but got %s", tc.expectedSpec, w.Config.CloudProviderSpec)
+			}
+		})
+	}
+}
+
+func TestApplyCollectsWorkersetErrors(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewConfigFromJSON([]byte(testOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.KubeOneWorkers.Value = map[string][]WorkerSetConfig{
+		"pool1": {WorkerSetConfig(`{"ami": 1}`)},
+		"pool2": {WorkerSetConfig(`{"region": "eu-central-1"}`)},
+		"pool3": {WorkerSetConfig(`{"replicas": "3"}`)},
+	}
+
+	err = c.Apply(&kubeonev1alpha1.KubeOneCluster{})
+	errs, ok := err.(ApplyErrors)
+	if !ok {
+		t.Fatalf("expected ApplyErrors, but got %v", err)
+	}
+	// pool1 fails to parse as AWS config, pool3 fails to parse as common
+	// config, pool2 is valid
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, but got %d: %v", len(errs), errs)
+	}
+}
+
+func TestApplyWorkersetOrder(t *testing.T) {
+	t.Parallel()
+
+	c := testConfig("aws", 3, map[string]int{"pool-c": 1, "pool-a": 1, "pool-b": 1})
+
+	cluster := &kubeonev1alpha1.KubeOneCluster{
+		Workers: []kubeonev1alpha1.WorkerConfig{{Name: "pool-b"}},
+	}
+	if err := c.Apply(cluster); err != nil {
+		t.Fatal(err)
+	}
+
+	names := make([]string, 0, len(cluster.Workers))
+	for _, w := range cluster.Workers {
+		names = append(names, w.Name)
+	}
+	expectedNames := []string{"pool-b", "pool-a", "pool-c"}
+	if !reflect.DeepEqual(names, expectedNames) {
+		t.Fatalf("expected workersets %v, but got %v", expectedNames, names)
+	}
+}
+
+func TestUpdateCommonWorkerConfigOperatingSystemSpec(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name             string
+		cfg              string
+		existingOSSpec   string
+		expectedOSSpec   string
+		expectedWarnings int
+	}{
+		{
+			name:           "ubuntu",
+			cfg:            `{"operatingSystem": "ubuntu", "operatingSystemSpec": [{"distUpgradeOnBoot": true, "version": "2191.5.0"}]}`,
+			expectedOSSpec: `{"distUpgradeOnBoot":true}`,
+		},
+		{
+			name:           "flatcar",
+			cfg:            `{"operatingSystem": "flatcar", "operatingSystemSpec": [{"distUpgradeOnBoot": true, "version": "2191.5.0", "systemd": {"units": []}}]}`,
+			expectedOSSpec: `{"systemd":{"units":[]},"version":"2191.5.0"}`,
+		},
+		{
+			name:             "multiple entries",
+			cfg:              `{"operatingSystem": "ubuntu", "operatingSystemSpec": [{"distUpgradeOnBoot": true, "kernelModules": ["a"]}, {"distUpgradeOnBoot": false, "kernelModules": ["b", "a"]}]}`,
+			expectedOSSpec:   `{"distUpgradeOnBoot":false,"kernelModules":["a","b"]}`,
+			expectedWarnings: 1,
+		},
+		{
+			name:           "merged with config.yaml",
+			cfg:            `{"operatingSystem": "ubuntu", "operatingSystemSpec": [{"distUpgradeOnBoot": true}]}`,
+			existingOSSpec: `{"distUpgradeOnBoot":false,"disableAutoUpdate":true}`,
+			expectedOSSpec: `{"disableAutoUpdate":true,"distUpgradeOnBoot":true}`,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := &Config{}
+			w := &kubeonev1alpha1.WorkerConfig{}
+			if tc.existingOSSpec != "" {
+				w.Config.OperatingSystemSpec = json.RawMessage(tc.existingOSSpec)
+			}
+			if err := c.updateCommonWorkerConfig(w, json.RawMessage(tc.cfg)); err != nil {
+				t.Fatal(err)
+			}
+			if string(w.Config.OperatingSystemSpec) != tc.expectedOSSpec {
+				t.Fatalf("expected operating system spec %s, but got %s", tc.expectedOSSpec, w.Config.OperatingSystemSpec)
+			}
+			if len(c.Warnings()) != tc.expectedWarnings {
+				t.Fatalf("expected %d warnings, but got %v", tc.expectedWarnings, c.Warnings())
+			}
+		})
+	}
+}
+
+func TestApplyWithoutControlPlaneHosts(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name          string
+		output        string
+		expectedError bool
+		expectedName  string
+	}{
+		{
+			name:          "no control plane section",
+			output:        `{}`,
+			expectedError: true,
+		},
+		{
+			name:         "no control plane addresses",
+			output:       `{"kubeone_hosts": {"value": {"control_plane": [{"cluster_name": "test", "cloud_provider": "aws"}]}}}`,
+			expectedName: "test",
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := NewConfigFromJSON([]byte(tc.output))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.ControlPlaneCount() != 0 {
+				t.Fatalf("expected no control plane hosts, but got %d", c.ControlPlaneCount())
+			}
+
+			hosts := []kubeonev1alpha1.HostConfig{{PublicAddress: "203.0.113.1"}}
+			cluster := &kubeonev1alpha1.KubeOneCluster{Hosts: hosts}
+			err = c.Apply(cluster)
+			if (err != nil) != tc.expectedError {
+				t.Fatalf("expected error = %v, but got %v", tc.expectedError, err)
+			}
+			if cluster.Name != tc.expectedName {
+				t.Fatalf("expected cluster name %q, but got %q", tc.expectedName, cluster.Name)
+			}
+			if !reflect.DeepEqual(cluster.Hosts, hosts) {
+				t.Fatalf("expected hosts from the cluster config to be kept, but got %+v", cluster.Hosts)
+			}
+		})
+	}
+}
+
+func TestOpenStackUpdateCloudConfig(t *testing.T) {
+	t.Parallel()
+
+	useOctavia := true
+
+	testcases := []struct {
+		name                string
+		openstack           openStackConfig
+		cloudConfig         string
+		expectedCloudConfig string
+	}{
+		{
+			name:                "no load balancer options",
+			cloudConfig:         "[Global]\n",
+			expectedCloudConfig: "[Global]\n",
+		},
+		{
+			name:                "load balancer options",
+			openstack:           openStackConfig{UseOctavia: &useOctavia, LBFloatingNetworkID: "net-1"},
+			cloudConfig:         "[Global]",
+			expectedCloudConfig: "[Global]\n[LoadBalancer]\nuse-octavia = true\nfloating-network-id = \"net-1\"\n",
+		},
+		{
+			name:                "existing load balancer section",
+			openstack:           openStackConfig{UseOctavia: &useOctavia},
+			cloudConfig:         "[LoadBalancer]\nuse-octavia = false\n",
+			expectedCloudConfig: "[LoadBalancer]\nuse-octavia = false\n",
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cloudConfig := tc.openstack.updateCloudConfig(tc.cloudConfig)
+			if cloudConfig != tc.expectedCloudConfig {
+				t.Fatalf("expected cloud config %q, but got %q", tc.expectedCloudConfig, cloudConfig)
+			}
+		})
+	}
+}
+
+func TestApplyCertSANs(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewConfigFromJSON([]byte(testOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.KubeOneCertSANs.Value = []string{"api.example.com", "10.0.0.100"}
+	c.KubeOneHosts.Value.ControlPlane[0].AdditionalSANs = []string{"internal-lb.example.com", "10.0.0.100"}
+
+	cluster := &kubeonev1alpha1.KubeOneCluster{
+		APIEndpoint: kubeonev1alpha1.APIEndpoint{
+			AlternativeNames: []string{"api.example.com"},
+		},
+	}
+	if err = c.Apply(cluster); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedNames := []string{"api.example.com", "10.0.0.100", "internal-lb.example.com"}
+	if !reflect.DeepEqual(cluster.APIEndpoint.AlternativeNames, expectedNames) {
+		t.Fatalf("expected alternative names %v, but got %v", expectedNames, cluster.APIEndpoint.AlternativeNames)
+	}
+}
+
+func TestNewConfigFromReader(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name          string
+		output        string
+		expectedError string
+	}{
+		{
+			name:   "valid output",
+			output: testOutput,
+		},
+		{
+			name:          "invalid value type",
+			output:        `{"kubeone_hosts": {"value": {"pod_cidr": 1}}}`,
+			expectedError: `failed to decode terraform output key "kubeone_hosts"`,
+		},
+		{
+			name:          "malformed json",
+			output:        `{"kubeone_hosts":`,
+			expectedError: "failed to decode terraform output",
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := NewConfigFromReader(strings.NewReader(tc.output))
+			if tc.expectedError == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if c.ControlPlaneCount() != 3 {
+					t.Fatalf("expected 3 control plane hosts, but got %d", c.ControlPlaneCount())
+				}
+				return
+			}
+			if err == nil || !strings.HasPrefix(err.Error(), tc.expectedError) {
+				t.Fatalf("expected error %q, but got %v", tc.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestConfigEquals(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name         string
+		a            string
+		b            string
+		annotationsA map[string]string
+		annotationsB map[string]string
+		expected     bool
+	}{
+		{
+			name:     "same output",
+			a:        testOutput,
+			b:        testOutput,
+			expected: true,
+		},
+		{
+			name:     "different formatting and key order",
+			a:        `{"kubeone_workers": {"value": {"pool": [{"replicas": 1, "sshPublicKeys": ["a"]}]}}}`,
+			b:        `{"kubeone_workers":{"value":{"pool":[{"sshPublicKeys":["a"],"replicas":1}]}}}`,
+			expected: true,
+		},
+		{
+			name:     "empty values",
+			a:        `{"kubeone_api": {"value": {"endpoint": ""}}, "kubeone_workers": {"value": {}}}`,
+			b:        `{}`,
+			expected: true,
+		},
+		{
+			name:     "different workerset spec",
+			a:        `{"kubeone_workers": {"value": {"pool": [{"replicas": 1}]}}}`,
+			b:        `{"kubeone_workers": {"value": {"pool": [{"replicas": 2}]}}}`,
+			expected: false,
+		},
+		{
+			name:     "different endpoint",
+			a:        `{"kubeone_api": {"value": {"endpoint": "a.example.com"}}}`,
+			b:        `{"kubeone_api": {"value": {"endpoint": "b.example.com"}}}`,
+			expected: false,
+		},
+		{
+			name:     "empty cloud provider",
+			a:        `{"kubeone_hosts": {"value": {"control_plane": [{"cloud_provider": ""}]}}}`,
+			b:        `{"kubeone_hosts": {"value": {"control_plane": [{}]}}}`,
+			expected: false,
+		},
+		{
+			name:     "empty workerset spec values",
+			a:        `{"kubeone_workers": {"value": {"pool": [{"replicas": 1, "labels": {}}]}}}`,
+			b:        `{"kubeone_workers": {"value": {"pool": [{"replicas": 1}]}}}`,
+			expected: false,
+		},
+		{
+			name:         "same annotations",
+			a:            testOutput,
+			b:            testOutput,
+			annotationsA: map[string]string{"a": "1"},
+			annotationsB: map[string]string{"a": "1"},
+			expected:     true,
+		},
+		{
+			name:         "different annotations",
+			a:            testOutput,
+			b:            testOutput,
+			annotationsA: map[string]string{"a": "1"},
+			annotationsB: map[string]string{"a": "2"},
+			expected:     false,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			a, err := NewConfigFromJSON([]byte(tc.a))
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := NewConfigFromJSON([]byte(tc.b))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.annotationsA != nil {
+				if err = a.AnnotateHosts(tc.annotationsA); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tc.annotationsB != nil {
+				if err = b.AnnotateHosts(tc.annotationsB); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if a.Equals(b) != tc.expected {
+				t.Fatalf("expected Equals to be %t", tc.expected)
+			}
+		})
+	}
+}
+
+func TestControlPlaneIPsReturnsCopies(t *testing.T) {
+	t.Parallel()
+
+	c := testConfig("aws", 2, nil)
+
+	public, private := c.ControlPlaneIPs()
+	public[0] = "203.0.113.1"
+	private[0] = "10.1.0.1"
+
+	public, private = c.ControlPlaneIPs()
+	if public[0] != "192.0.2.1" {
+		t.Errorf("expected public address to be unchanged, but got %q", public[0])
+	}
+	if private[0] != "10.0.0.1" {
+		t.Errorf("expected private address to be unchanged, but got %q", private[0])
+	}
+}
+
+func TestConfigHelper(t *testing.T) {
+	t.Parallel()
+
+	c := testConfig("hetzner", 2, map[string]int{"pool": 3})
+
+	cluster := &kubeonev1alpha1.KubeOneCluster{}
+	if err := c.Apply(cluster); err != nil {
+		t.Fatal(err)
+	}
+	if len(cluster.Hosts) != 2 {
+		t.Fatalf("expected 2 hosts, but got %d", len(cluster.Hosts))
+	}
+	if cluster.CloudProvider.Name != kubeonev1alpha1.CloudProviderNameHetzner {
+		t.Fatalf("expected cloud provider hetzner, but got %q", cluster.CloudProvider.Name)
+	}
+	if len(cluster.Workers) != 1 || *cluster.Workers[0].Replicas != 3 {
+		t.Fatalf("expected a single workerset with 3 replicas, but got %+v", cluster.Workers)
+	}
+}
+
+func TestControlPlaneHostConfigs(t *testing.T) {
+	t.Parallel()
+
+	c := testConfig("aws", 3, nil)
+
+	hosts, err := c.ControlPlaneHostConfigs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 3 {
+		t.Fatalf("expected 3 hosts, but got %d", len(hosts))
+	}
+	for i, host := range hosts {
+		if host.ID != i {
+			t.Errorf("expected host %d to have ID %d, but got %d", i, i, host.ID)
+		}
+	}
+}
+
+func TestGCEUpdateCloudConfig(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name                string
+		gce                 gceConfig
+		cloudConfig         string
+		expectedCloudConfig string
+	}{
+		{
+			name:                "no project ID",
+			cloudConfig:         "",
+			expectedCloudConfig: "",
+		},
+		{
+			name:                "project ID",
+			gce:                 gceConfig{ProjectID: "my-project"},
+			expectedCloudConfig: "[global]\nproject-id = \"my-project\"\n",
+		},
+		{
+			name:                "existing global section",
+			gce:                 gceConfig{ProjectID: "my-project"},
+			cloudConfig:         "[global]\nproject-id = \"other\"\n",
+			expectedCloudConfig: "[global]\nproject-id = \"other\"\n",
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cloudConfig := tc.gce.updateCloudConfig(tc.cloudConfig)
+			if cloudConfig != tc.expectedCloudConfig {
+				t.Fatalf("expected cloud config %q, but got %q", tc.expectedCloudConfig, cloudConfig)
+			}
+		})
+	}
+}
+
+func TestSummaryTable(t *testing.T) {
+	t.Parallel()
+
+	c := testConfig("aws", 2, nil)
+	c.KubeOneWorkers.Value = map[string][]WorkerSetConfig{
+		"pool-b": {WorkerSetConfig(`{"replicas": 3, "instanceType": "t3.medium"}`)},
+		"pool-a": {WorkerSetConfig(`{}`)},
+	}
+
+	expected := `Cluster name:               test
+Cloud provider:             aws
+Control plane public IPs:   192.0.2.1, 192.0.2.2
+Control plane private IPs:  10.0.0.1, 10.0.0.2
+
+WORKERSET  PROVIDER  REPLICAS  INSTANCE TYPE
+pool-a     aws       -         -
+pool-b     aws       3         t3.medium
+`
+	if table := c.SummaryTable(); table != expected {
+		t.Fatalf("expected table:\n%s\nbut got:\n%s", expected, table)
+	}
+}
+
+func TestWriteToFile(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "kubeone-terraform")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c, err := NewConfigFromJSON([]byte(testOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "tf.json")
+	if err = c.WriteToFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	written, err := NewConfigFromJSON(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Equals(written) {
+		t.Fatalf("expected written config to equal the original one, but got %s", buf)
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expected only the output file to be left, but got %d files", len(files))
+	}
+}
+
+func TestApplyInvalidWorkersetName(t *testing.T) {
+	t.Parallel()
+
+	c := testConfig("aws", 1, map[string]int{"valid-pool": 1, "Invalid_Pool": 1})
+
+	cluster := &kubeonev1alpha1.KubeOneCluster{}
+	err := c.Apply(cluster)
+	errs, ok := err.(ApplyErrors)
+	if !ok || len(errs) != 1 {
+		t.Fatalf("expected a single error, but got %v", err)
+	}
+	if !strings.Contains(errs[0].Error(), `"Invalid_Pool"`) {
+		t.Fatalf("expected error about the invalid workerset name, but got %v", errs[0])
+	}
+	if len(cluster.Workers) != 1 || cluster.Workers[0].Name != "valid-pool" {
+		t.Fatalf("expected only the valid workerset, but got %+v", cluster.Workers)
+	}
+}
+
+func TestFindWorkerSet(t *testing.T) {
+	t.Parallel()
+
+	c := testConfig("gce", 1, nil)
+	c.KubeOneWorkers.Value = map[string][]WorkerSetConfig{
+		"pool": {WorkerSetConfig(`{"replicas": 2, "machineType": "n1-standard-2"}`)},
+	}
+
+	ws, ok := c.FindWorkerSet("pool")
+	if !ok {
+		t.Fatal("expected workerset pool to be found")
+	}
+	if ws.Replicas == nil || *ws.Replicas != 2 {
+		t.Errorf("expected 2 replicas, but got %v", ws.Replicas)
+	}
+	if ws.InstanceType != "n1-standard-2" {
+		t.Errorf("expected instance type n1-standard-2, but got %q", ws.InstanceType)
+	}
+
+	if _, ok = c.FindWorkerSet("missing"); ok {
+		t.Fatal("expected workerset missing not to be found")
+	}
+
+	// terraform wraps every workerset in a single element list, any other
+	// length is not applied
+	c.KubeOneWorkers.Value["multiple"] = []WorkerSetConfig{WorkerSetConfig(`{"replicas": 1}`), WorkerSetConfig(`{"replicas": 2}`)}
+	if _, ok = c.FindWorkerSet("multiple"); ok {
+		t.Fatal("expected workerset multiple not to be found")
+	}
+}
+
+func TestApplyWithoutCloudProvider(t *testing.T) {
+	t.Parallel()
+
+	c := testConfig("", 1, nil)
+	c.KubeOneHosts.Value.ControlPlane[0].CloudProvider = nil
+
+	unchanged := &kubeonev1alpha1.KubeOneCluster{}
+	err := c.Apply(unchanged)
+	if err == nil || err.Error() != "cloud provider must be specified in terraform output or cluster config" {
+		t.Fatalf("expected missing cloud provider error, but got %v", err)
+	}
+	if !reflect.DeepEqual(unchanged, &kubeonev1alpha1.KubeOneCluster{}) {
+		t.Fatalf("expected cluster to be left unchanged, but got %+v", unchanged)
+	}
+
+	cluster := &kubeonev1alpha1.KubeOneCluster{
+		CloudProvider: kubeonev1alpha1.CloudProviderSpec{Name: kubeonev1alpha1.CloudProviderNameAWS},
+	}
+	if err = c.Apply(cluster); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestToJSON(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewConfigFromJSON([]byte(testOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.KubeOneWorkers.Value = map[string][]WorkerSetConfig{
+		"pool": {WorkerSetConfig(`{"replicas": 3, "diskSize": 50}`)},
+	}
+
+	buf, err := c.ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(buf), "{\n  \"kubeone_api\": {\n    \"value\": {\n      \"endpoint\": \"lb.example.com\"") {
+		t.Fatalf("expected sorted and indented output, but got %s", buf)
+	}
+
+	parsed, err := NewConfigFromJSON(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Equals(parsed) {
+		t.Fatalf("expected parsed output to equal the original config, but got %s", buf)
+	}
+
+	again, err := parsed.ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != string(buf) {
+		t.Fatalf("expected output to be stable, but got:\n%s\nand:\n%s", buf, again)
+	}
+}
+
+func TestApplyCNIPlugin(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name             string
+		cni              *kubeonev1alpha1.CNI
+		expectedProvider kubeonev1alpha1.CNIProvider
+	}{
+		{
+			name:             "not configured",
+			expectedProvider: kubeonev1alpha1.CNIProviderWeaveNet,
+		},
+		{
+			name:             "configured in config.yaml",
+			cni:              &kubeonev1alpha1.CNI{Provider: kubeonev1alpha1.CNIProviderCanal},
+			expectedProvider: kubeonev1alpha1.CNIProviderCanal,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := testConfig("aws", 1, nil)
+			c.KubeOneHosts.Value.CNIPlugin = string(kubeonev1alpha1.CNIProviderWeaveNet)
+
+			cluster := &kubeonev1alpha1.KubeOneCluster{}
+			cluster.ClusterNetwork.CNI = tc.cni
+			if err := c.Apply(cluster); err != nil {
+				t.Fatal(err)
+			}
+			if cluster.ClusterNetwork.CNI.Provider != tc.expectedProvider {
+				t.Fatalf("expected CNI provider %q, but got %q", tc.expectedProvider, cluster.ClusterNetwork.CNI.Provider)
+			}
+		})
+	}
+}
+
+func TestUpdateCommonWorkerConfigSSHPublicKeys(t *testing.T) {
+	t.Parallel()
+
+	const (
+		keyA = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIEE4zonY3KKEq2Cgo9t8MdgFzBNwMUme1fhiyxL5DusN a"
+		keyB = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIJ0pgRVCe6L7GHrrk2lTRmlQ9tSbQSK4JBbv/iJaWG2X b"
+	)
+
+	testcases := []struct {
+		name         string
+		existingKeys []string
+		keys         []string
+		expectedKeys []string
+		expectError  bool
+	}{
+		{
+			name:         "valid keys",
+			keys:         []string{keyA, keyB},
+			expectedKeys: []string{keyA, keyB},
+		},
+		{
+			name:         "duplicate keys with different comments",
+			existingKeys: []string{keyA},
+			keys:         []string{keyA + "-other", keyB, keyB},
+			expectedKeys: []string{keyA, keyB},
+		},
+		{
+			name:        "invalid key",
+			keys:        []string{keyA, "ssh-ed25519 invalid"},
+			expectError: true,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := json.Marshal(map[string][]string{"sshPublicKeys": tc.keys})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c := &Config{}
+			w := &kubeonev1alpha1.WorkerConfig{}
+			w.Config.SSHPublicKeys = tc.existingKeys
+			err = c.updateCommonWorkerConfig(w, cfg)
+			if tc.expectError {
+				if err == nil {
+					t.Fatal("expected an error, but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(w.Config.SSHPublicKeys, tc.expectedKeys) {
+				t.Fatalf("expected keys %v, but got %v", tc.expectedKeys, w.Config.SSHPublicKeys)
+			}
+		})
+	}
+}
+
+func TestSSHHostKeys(t *testing.T) {
+	t.Parallel()
+
+	const hostKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIEE4zonY3KKEq2Cgo9t8MdgFzBNwMUme1fhiyxL5DusN"
+
+	testcases := []struct {
+		name             string
+		output           string
+		expectedHostKeys map[string]string
+		expectError      bool
+	}{
+		{
+			name:             "no host keys",
+			output:           `{}`,
+			expectedHostKeys: map[string]string{},
+		},
+		{
+			name:             "host keys",
+			output:           `{"kubeone_ssh_host_keys": {"value": {"192.0.2.1": "` + hostKey + `\n"}}}`,
+			expectedHostKeys: map[string]string{"192.0.2.1": hostKey},
+		},
+		{
+			name:        "invalid host key",
+			output:      `{"kubeone_ssh_host_keys": {"value": {"192.0.2.1": "ssh-ed25519 invalid"}}}`,
+			expectError: true,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := NewConfigFromJSON([]byte(tc.output))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			hostKeys, err := c.SSHHostKeys()
+			if tc.expectError {
+				if err == nil {
+					t.Fatal("expected an error, but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(hostKeys, tc.expectedHostKeys) {
+				t.Fatalf("expected host keys %v, but got %v", tc.expectedHostKeys, hostKeys)
+			}
+		})
+	}
+}
+
+// TestConfigFromEnv is not run in parallel, as it modifies the environment
+func TestConfigFromEnv(t *testing.T) {
+	const output = `{"kubeone_hosts": {"value": {"control_plane": [{"cluster_name": "test"}]}}}`
+
+	testcases := []struct {
+		name                string
+		value               string
+		expectedClusterName string
+		expectError         bool
+	}{
+		{
+			name:        "not set",
+			expectError: true,
+		},
+		{
+			name:                "plain JSON",
+			value:               "  " + output + "\n",
+			expectedClusterName: "test",
+		},
+		{
+			name:                "base64 encoded JSON",
+			value:               base64.StdEncoding.EncodeToString([]byte(output)),
+			expectedClusterName: "test",
+		},
+		{
+			name:        "invalid base64",
+			value:       "not base64",
+			expectError: true,
+		},
+		{
+			name:        "invalid JSON",
+			value:       `{"kubeone_hosts": `,
+			expectError: true,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			defer os.Unsetenv(OutputEnvVar)
+			if err := os.Setenv(OutputEnvVar, tc.value); err != nil {
+				t.Fatal(err)
+			}
+
+			c, err := ConfigFromEnv()
+			if tc.expectError {
+				if err == nil {
+					t.Fatal("expected an error, but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if name, _ := c.ControlPlaneClusterName(); name != tc.expectedClusterName {
+				t.Fatalf("expected cluster name %q, but got %q", tc.expectedClusterName, name)
+			}
+		})
+	}
+}
+
+func TestApplyToHostConfigs(t *testing.T) {
+	t.Parallel()
+
+	c := testConfig("aws", 2, nil)
+	c.KubeOneHosts.Value.ControlPlane[0].SSHUser = ""
+
+	hosts := []kubeonev1alpha1.HostConfig{
+		{PublicAddress: "198.51.100.1", SSHUsername: "ubuntu", Hostname: "cp-1"},
+	}
+	updated, err := c.ApplyToHostConfigs(hosts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []kubeonev1alpha1.HostConfig{
+		{ID: 0, PublicAddress: "192.0.2.1", PrivateAddress: "10.0.0.1", SSHUsername: "ubuntu", Hostname: "cp-1"},
+		{ID: 1, PublicAddress: "192.0.2.2", PrivateAddress: "10.0.0.2"},
+	}
+	if !reflect.DeepEqual(updated, expected) {
+		t.Fatalf("expected hosts %+v, but got %+v", expected, updated)
+	}
+	if hosts[0].PublicAddress != "198.51.100.1" {
+		t.Fatalf("expected given hosts not to be modified, but got %+v", hosts)
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name          string
+		output        string
+		opts          ParseOptions
+		expectedError string
+	}{
+		{
+			name:   "unknown fields are ignored by default",
+			output: `{"kubeone_hosts": {"sensitive": false, "value": {"unknown": 1}}}`,
+		},
+		{
+			name:          "unknown fields are rejected in strict mode",
+			output:        `{"kubeone_hosts": {"sensitive": false, "value": {"unknown": 1}}}`,
+			opts:          ParseOptions{Strict: true},
+			expectedError: `failed to decode terraform output key "kubeone_hosts"`,
+		},
+		{
+			name:   "valid output in strict mode",
+			output: testOutput,
+			opts:   ParseOptions{Strict: true, ValidateOnParse: true},
+		},
+		{
+			name:          "no control plane hosts",
+			output:        `{"kubeone_hosts": {"value": {"pod_cidr": "10.244.0.0/16"}}}`,
+			opts:          ParseOptions{ValidateOnParse: true},
+			expectedError: "no control plane hosts are given",
+		},
+		{
+			name:   "control plane without addresses is accepted like by Apply",
+			output: `{"kubeone_hosts": {"value": {"control_plane": [{"cluster_name": "test", "ssh_port": "22"}]}}}`,
+			opts:   ParseOptions{ValidateOnParse: true},
+		},
+		{
+			name:          "validation error",
+			output:        `{"kubeone_hosts": {"value": {"control_plane": [{"public_address": ["192.0.2.1"], "ssh_port": "ssh"}]}}}`,
+			opts:          ParseOptions{ValidateOnParse: true},
+			expectedError: `failed to convert ssh port string "ssh" to int`,
+		},
+		{
+			name:          "workspace in error",
+			output:        `{"kubeone_hosts": {"value": {"control_plane": [{"public_address": ["192.0.2.1"]}]}}, "kubeone_workers": {"value": {"Invalid_Pool": []}}}`,
+			opts:          ParseOptions{Workspace: "staging", ValidateOnParse: true},
+			expectedError: `terraform workspace "staging": invalid workerset name "Invalid_Pool"`,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseConfig(strings.NewReader(tc.output), tc.opts)
+			if tc.expectedError == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.HasPrefix(err.Error(), tc.expectedError) {
+				t.Fatalf("expected error %q, but got %v", tc.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestUpdateAWSWorkersetAdditionalSecurityGroups(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name         string
+		existingSpec string
+		cfg          string
+		expectedIDs  []string
+	}{
+		{
+			name:        "appended to terraform security groups",
+			cfg:         `{"securityGroupIDs": ["sg-1"], "additionalSecurityGroupIDs": ["sg-2", "sg-1"]}`,
+			expectedIDs: []string{"sg-1", "sg-2"},
+		},
+		{
+			name:         "appended to config.yaml security groups",
+			existingSpec: `{"securityGroupIDs": ["sg-0"]}`,
+			cfg:          `{"securityGroupIDs": ["sg-1"], "additionalSecurityGroupIDs": ["sg-2"]}`,
+			expectedIDs:  []string{"sg-0", "sg-2"},
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := &Config{}
+			w := &kubeonev1alpha1.WorkerConfig{}
+			if tc.existingSpec != "" {
+				w.Config.CloudProviderSpec = json.RawMessage(tc.existingSpec)
+			}
+			if err := c.updateAWSWorkerset(w, json.RawMessage(tc.cfg)); err != nil {
+				t.Fatal(err)
+			}
+
+			var spec struct {
+				SecurityGroupIDs []string `json:"securityGroupIDs"`
+			}
+			if err := json.Unmarshal(w.Config.CloudProviderSpec, &spec); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(spec.SecurityGroupIDs, tc.expectedIDs) {
+				t.Fatalf("expected security groups %v, but got %v", tc.expectedIDs, spec.SecurityGroupIDs)
+			}
+		})
+	}
+}
+
+func TestControlPlaneClusterName(t *testing.T) {
+	t.Parallel()
+
+	name, err := testConfig("aws", 1, nil).ControlPlaneClusterName()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "test" {
+		t.Fatalf("expected cluster name test, but got %q", name)
+	}
+
+	if _, err = (&Config{}).ControlPlaneClusterName(); err == nil {
+		t.Fatal("expected an error, but got nil")
+	}
+}
+
+func TestUpdateCommonWorkerConfigRollingUpdate(t *testing.T) {
+	t.Parallel()
+
+	maxSurge := intstr.FromInt(2)
+
+	c := &Config{}
+	w := &kubeonev1alpha1.WorkerConfig{
+		RollingUpdate: &kubeonev1alpha1.RollingUpdateConfig{MaxSurge: &maxSurge},
+	}
+	if err := c.updateCommonWorkerConfig(w, json.RawMessage(`{"maxSurge": "50%", "maxUnavailable": 1}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &kubeonev1alpha1.RollingUpdateConfig{
+		MaxSurge:       &maxSurge,
+		MaxUnavailable: &intstr.IntOrString{Type: intstr.Int, IntVal: 1},
+	}
+	if !reflect.DeepEqual(w.RollingUpdate, expected) {
+		t.Fatalf("expected rolling update %+v, but got %+v", expected, w.RollingUpdate)
+	}
+}
+
+func TestUpdateGCEWorkersetLabelsAndTags(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name         string
+		cfg          string
+		expectedSpec string
+		expectedErr  bool
+	}{
+		{
+			name:         "labels map and tags list",
+			cfg:          `{"labels": {"team": "a"}, "tags": ["firewall-web"]}`,
+			expectedSpec: `{"labels":{"team":"a"},"preemptible":false,"tags":["firewall-web"]}`,
+		},
+		{
+			name:        "labels list is rejected",
+			cfg:         `{"labels": ["team"]}`,
+			expectedErr: true,
+		},
+		{
+			name:        "tags map is rejected",
+			cfg:         `{"tags": {"team": "a"}}`,
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := &Config{}
+			w := &kubeonev1alpha1.WorkerConfig{}
+			err := c.updateGCEWorkerset(w, json.RawMessage(tc.cfg))
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error %t, but got %v", tc.expectedErr, err)
+			}
+			if tc.expectedErr {
+				return
+			}
+			if string(w.Config.CloudProviderSpec) != tc.expectedSpec {
+				t.Fatalf("expected spec %s, but got %s", tc.expectedSpec, w.Config.CloudProviderSpec)
+			}
+		})
+	}
+}
+
+func TestApplyMachineControllerEnabled(t *testing.T) {
+	t.Parallel()
+
+	enabled, disabled := true, false
+
+	testcases := []struct {
+		name              string
+		machineController *kubeonev1alpha1.MachineControllerConfig
+		enabled           *bool
+		version           string
+		expectedDeploy    *bool
+	}{
+		{
+			name: "not set",
+		},
+		{
+			name:           "disabled",
+			enabled:        &disabled,
+			version:        "v1.1.9",
+			expectedDeploy: &disabled,
+		},
+		{
+			name:              "configured in config.yaml",
+			machineController: &kubeonev1alpha1.MachineControllerConfig{Deploy: true},
+			enabled:           &disabled,
+			expectedDeploy:    &enabled,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := testConfig("aws", 1, nil)
+			c.KubeOneHosts.Value.MachineControllerEnabled = tc.enabled
+			c.KubeOneHosts.Value.MachineControllerVersion = tc.version
+
+			cluster := &kubeonev1alpha1.KubeOneCluster{MachineController: tc.machineController}
+			if err := c.Apply(cluster); err != nil {
+				t.Fatal(err)
+			}
+			if tc.expectedDeploy == nil {
+				if cluster.MachineController != nil {
+					t.Fatalf("expected machine-controller config to be unset, but got %+v", cluster.MachineController)
+				}
+				return
+			}
+			if cluster.MachineController.Deploy != *tc.expectedDeploy {
+				t.Fatalf("expected deploy %t, but got %t", *tc.expectedDeploy, cluster.MachineController.Deploy)
+			}
+		})
+	}
+}
+
+func TestApplyPartial(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name            string
+		sections        []string
+		expectedHosts   int
+		expectedPods    string
+		expectedWorkers int
+		expectedErr     bool
+	}{
+		{
+			name:            "workers only",
+			sections:        []string{SectionWorkers},
+			expectedWorkers: 1,
+		},
+		{
+			name:          "hosts and networking",
+			sections:      []string{SectionNetworking, SectionHosts},
+			expectedHosts: 2,
+			expectedPods:  "10.244.0.0/16",
+		},
+		{
+			name:        "unknown section",
+			sections:    []string{"storage"},
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := testConfig("aws", 2, map[string]int{"pool": 1})
+			c.KubeOneHosts.Value.PodCIDR = "10.244.0.0/16"
+
+			cluster := &kubeonev1alpha1.KubeOneCluster{}
+			err := c.ApplyPartial(cluster, tc.sections...)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error %t, but got %v", tc.expectedErr, err)
+			}
+			if tc.expectedErr {
+				return
+			}
+			if len(cluster.Hosts) != tc.expectedHosts {
+				t.Errorf("expected %d hosts, but got %d", tc.expectedHosts, len(cluster.Hosts))
+			}
+			if cluster.ClusterNetwork.PodSubnet != tc.expectedPods {
+				t.Errorf("expected pod subnet %q, but got %q", tc.expectedPods, cluster.ClusterNetwork.PodSubnet)
+			}
+			if len(cluster.Workers) != tc.expectedWorkers {
+				t.Errorf("expected %d workersets, but got %d", tc.expectedWorkers, len(cluster.Workers))
+			}
+		})
+	}
+}
+
+func TestApplyPodSecurityStandard(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name           string
+		standard       string
+		psp            *kubeonev1alpha1.PodSecurityPolicy
+		expectedEnable bool
+		expectedErr    bool
+	}{
+		{
+			name:     "privileged",
+			standard: "privileged",
+		},
+		{
+			name:           "restricted",
+			standard:       "restricted",
+			expectedEnable: true,
+		},
+		{
+			name:     "configured in config.yaml",
+			standard: "baseline",
+			psp:      &kubeonev1alpha1.PodSecurityPolicy{Enable: false},
+		},
+		{
+			name:        "invalid",
+			standard:    "strict",
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := testConfig("aws", 1, nil)
+			c.KubeOneHosts.Value.PodSecurityStandard = tc.standard
+
+			cluster := &kubeonev1alpha1.KubeOneCluster{}
+			cluster.Features.PodSecurityPolicy = tc.psp
+			err := c.Apply(cluster)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error %t, but got %v", tc.expectedErr, err)
+			}
+			if tc.expectedErr {
+				return
+			}
+			if cluster.Features.PodSecurityPolicy.Enable != tc.expectedEnable {
+				t.Fatalf("expected PodSecurityPolicy enabled %t, but got %t", tc.expectedEnable, cluster.Features.PodSecurityPolicy.Enable)
+			}
+		})
+	}
+}
+
+func TestUpdateCommonWorkerConfigDiskEncryptionKeyID(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name          string
+		existingKeyID string
+		expectedKeyID string
+	}{
+		{
+			name:          "from terraform output",
+			expectedKeyID: "tf-key",
+		},
+		{
+			name:          "configured in config.yaml",
+			existingKeyID: "yaml-key",
+			expectedKeyID: "yaml-key",
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := &Config{}
+			w := &kubeonev1alpha1.WorkerConfig{}
+			w.Config.DiskEncryptionKeyID = tc.existingKeyID
+			if err := c.updateCommonWorkerConfig(w, json.RawMessage(`{"diskEncryptionKeyID": "tf-key"}`)); err != nil {
+				t.Fatal(err)
+			}
+			if w.Config.DiskEncryptionKeyID != tc.expectedKeyID {
+				t.Fatalf("expected disk encryption key %q, but got %q", tc.expectedKeyID, w.Config.DiskEncryptionKeyID)
+			}
+		})
+	}
+}
+
+func TestWorkerSetByName(t *testing.T) {
+	t.Parallel()
+
+	c := testConfig("aws", 1, map[string]int{"pool": 2})
+	c.KubeOneWorkers.Value["empty"] = []WorkerSetConfig{}
+
+	testcases := []struct {
+		name          string
+		expectedSpec  string
+		expectedFound bool
+	}{
+		{
+			name:          "pool",
+			expectedSpec:  `{"replicas": 2}`,
+			expectedFound: true,
+		},
+		{
+			name: "empty",
+		},
+		{
+			name: "missing",
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			spec, found := c.WorkerSetByName(tc.name)
+			if found != tc.expectedFound {
+				t.Fatalf("expected found %t, but got %t", tc.expectedFound, found)
+			}
+			if string(spec) != tc.expectedSpec {
+				t.Fatalf("expected spec %s, but got %s", tc.expectedSpec, spec)
+			}
+		})
+	}
+}
+
+// manyWorkerSets returns a config with the given number of workersets
+func manyWorkerSets(n int) *Config {
+	workerSets := make(map[string]int, n)
+	for i := 0; i < n; i++ {
+		workerSets[fmt.Sprintf("pool-%d", i)] = i
+	}
+	c := testConfig("aws", 3, workerSets)
+	c.KubeOneWorkers.Value["pool-0"] = []WorkerSetConfig{
+		WorkerSetConfig(`{"replicas": 1, "operatingSystemSpec": [{"distUpgradeOnBoot": true}, {"distUpgradeOnBoot": false}]}`),
+	}
+	c.KubeOneWorkers.Value["Invalid_Name"] = []WorkerSetConfig{WorkerSetConfig(`{}`)}
+
+	return c
+}
+
+func TestApplyConcurrent(t *testing.T) {
+	t.Parallel()
+
+	sequential := manyWorkerSets(20)
+	expectedCluster := &kubeonev1alpha1.KubeOneCluster{}
+	expectedErr := sequential.Apply(expectedCluster)
+
+	concurrent := manyWorkerSets(20)
+	cluster := &kubeonev1alpha1.KubeOneCluster{}
+	err := concurrent.ApplyConcurrent(cluster)
+
+	if err == nil || expectedErr == nil || err.Error() != expectedErr.Error() {
+		t.Errorf("expected error %v, but got %v", expectedErr, err)
+	}
+	if !reflect.DeepEqual(cluster, expectedCluster) {
+		t.Errorf("expected the same cluster as applied sequentially")
+	}
+	if !reflect.DeepEqual(concurrent.Warnings(), sequential.Warnings()) {
+		t.Errorf("expected warnings %v, but got %v", sequential.Warnings(), concurrent.Warnings())
+	}
+}
+
+func BenchmarkApply(b *testing.B) {
+	c := manyWorkerSets(50)
+	for i := 0; i < b.N; i++ {
+		_ = c.Apply(&kubeonev1alpha1.KubeOneCluster{})
+	}
+}
+
+func BenchmarkApplyConcurrent(b *testing.B) {
+	c := manyWorkerSets(50)
+	for i := 0; i < b.N; i++ {
+		_ = c.ApplyConcurrent(&kubeonev1alpha1.KubeOneCluster{})
+	}
+}
+
+func TestApplyOIDC(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name              string
+		oidc              *kubeonev1alpha1.OpenIDConnect
+		expectedIssuerURL string
+	}{
+		{
+			name:              "not configured",
+			expectedIssuerURL: "https://dex.example.com",
+		},
+		{
+			name: "configured in config.yaml",
+			oidc: &kubeonev1alpha1.OpenIDConnect{
+				Enable: true,
+				Config: kubeonev1alpha1.OpenIDConnectConfig{IssuerURL: "https://accounts.example.com", ClientID: "kubernetes"},
+			},
+			expectedIssuerURL: "https://accounts.example.com",
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := testConfig("aws", 1, nil)
+			c.KubeOneHosts.Value.OIDCIssuerURL = "https://dex.example.com"
+			c.KubeOneHosts.Value.OIDCClientID = "kubeone"
+			c.KubeOneHosts.Value.OIDCGroupsClaim = "groups"
+
+			cluster := &kubeonev1alpha1.KubeOneCluster{}
+			cluster.Features.OpenIDConnect = tc.oidc
+			if err := c.Apply(cluster); err != nil {
+				t.Fatal(err)
+			}
+			oidc := cluster.Features.OpenIDConnect
+			if !oidc.Enable {
+				t.Fatalf("expected OpenID Connect to be enabled")
+			}
+			if oidc.Config.IssuerURL != tc.expectedIssuerURL {
+				t.Fatalf("expected issuer URL %q, but got %q", tc.expectedIssuerURL, oidc.Config.IssuerURL)
+			}
+		})
+	}
+}
+
+func TestApplyInternalEndpoint(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name                 string
+		internalHost         string
+		expectedInternalHost string
+	}{
+		{
+			name:                 "not configured",
+			expectedInternalHost: "internal-lb.example.com",
+		},
+		{
+			name:                 "configured in config.yaml",
+			internalHost:         "api.internal.example.com",
+			expectedInternalHost: "api.internal.example.com",
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := testConfig("aws", 1, nil)
+			c.KubeOneAPI.Value.Endpoint = "api.example.com"
+			c.KubeOneAPI.Value.InternalEndpoint = "internal-lb.example.com"
+
+			cluster := &kubeonev1alpha1.KubeOneCluster{}
+			cluster.APIEndpoint.InternalHost = tc.internalHost
+			if err := c.Apply(cluster); err != nil {
+				t.Fatal(err)
+			}
+			if cluster.APIEndpoint.Host != "api.example.com" {
+				t.Errorf("expected host %q, but got %q", "api.example.com", cluster.APIEndpoint.Host)
+			}
+			if cluster.APIEndpoint.InternalHost != tc.expectedInternalHost {
+				t.Errorf("expected internal host %q, but got %q", tc.expectedInternalHost, cluster.APIEndpoint.InternalHost)
+			}
+		})
+	}
+}
+
+func TestUpdateVSphereWorkersetTemplateInventoryPath(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name                  string
+		cfg                   string
+		expectedName          string
+		expectedInventoryPath string
+	}{
+		{
+			name:         "template name",
+			cfg:          `{"templateVMName": "ubuntu-20.04"}`,
+			expectedName: "ubuntu-20.04",
+		},
+		{
+			name:                  "template inventory path",
+			cfg:                   `{"templateVMName": "/DC/vm/Templates/ubuntu-20.04"}`,
+			expectedName:          "ubuntu-20.04",
+			expectedInventoryPath: "/DC/vm/Templates/ubuntu-20.04",
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := &Config{}
+			w := &kubeonev1alpha1.WorkerConfig{}
+			if err := c.updateVSphereWorkerset(w, json.RawMessage(tc.cfg)); err != nil {
+				t.Fatal(err)
+			}
+
+			var spec struct {
+				TemplateVMName          string `json:"templateVMName"`
+				TemplateVMInventoryPath string `json:"templateVMInventoryPath"`
+			}
+			if err := json.Unmarshal(w.Config.CloudProviderSpec, &spec); err != nil {
+				t.Fatal(err)
+			}
+			if spec.TemplateVMName != tc.expectedName {
+				t.Errorf("expected template name %q, but got %q", tc.expectedName, spec.TemplateVMName)
+			}
+			if spec.TemplateVMInventoryPath != tc.expectedInventoryPath {
+				t.Errorf("expected template inventory path %q, but got %q", tc.expectedInventoryPath, spec.TemplateVMInventoryPath)
+			}
+		})
+	}
+}
+
+func TestHasControlPlaneAndWorkers(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name                 string
+		config               *Config
+		expectedControlPlane bool
+		expectedWorkers      bool
+	}{
+		{
+			name:   "empty",
+			config: &Config{},
+		},
+		{
+			name:                 "control plane only",
+			config:               testConfig("aws", 1, nil),
+			expectedControlPlane: true,
+		},
+		{
+			name:                 "control plane and workers",
+			config:               testConfig("aws", 1, map[string]int{"pool": 1}),
+			expectedControlPlane: true,
+			expectedWorkers:      true,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.config.HasControlPlane() != tc.expectedControlPlane {
+				t.Errorf("expected HasControlPlane %t, but got %t", tc.expectedControlPlane, tc.config.HasControlPlane())
+			}
+			if tc.config.HasWorkers() != tc.expectedWorkers {
+				t.Errorf("expected HasWorkers %t, but got %t", tc.expectedWorkers, tc.config.HasWorkers())
+			}
+		})
+	}
+}
+
+func TestApplyPartialWithoutControlPlane(t *testing.T) {
+	t.Parallel()
+
+	c := &Config{}
+	c.KubeOneWorkers.Value = map[string][]WorkerSetConfig{
+		"pool": {WorkerSetConfig(`{"replicas": 1}`)},
+	}
+
+	cluster := &kubeonev1alpha1.KubeOneCluster{}
+	cluster.CloudProvider.Name = kubeonev1alpha1.CloudProviderNameAWS
+	if err := c.ApplyPartial(cluster, SectionAPI, SectionNetworking, SectionWorkers); err != nil {
+		t.Fatal(err)
+	}
+	if len(cluster.Workers) != 1 {
+		t.Fatalf("expected 1 workerset, but got %d", len(cluster.Workers))
+	}
+
+	if err := c.ApplyPartial(cluster, SectionHosts); err == nil {
+		t.Fatalf("expected an error applying the hosts without control plane hosts")
+	}
+}
+
+func TestApplyToWorkerSets(t *testing.T) {
+	t.Parallel()
+
+	c := testConfig("aws", 1, map[string]int{"cpu": 3, "gpu": 2})
+	c.KubeOneAPI.Value.Endpoint = "api.example.com"
+
+	cpuReplicas := 1
+	cluster := &kubeonev1alpha1.KubeOneCluster{
+		Workers: []kubeonev1alpha1.WorkerConfig{{Name: "cpu", Replicas: &cpuReplicas}},
+	}
+	cluster.CloudProvider.Name = kubeonev1alpha1.CloudProviderNameAWS
+
+	if err := c.ApplyToWorkerSets(cluster, []string{"gpu"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(cluster.Workers) != 2 {
+		t.Fatalf("expected 2 workersets, but got %d", len(cluster.Workers))
+	}
+	if cluster.Workers[0].Config.CloudProviderSpec != nil {
+		t.Errorf("expected workerset cpu to be unchanged, but got spec %s", cluster.Workers[0].Config.CloudProviderSpec)
+	}
+	if gpu := cluster.Workers[1]; gpu.Name != "gpu" || *gpu.Replicas != 2 {
+		t.Errorf("expected workerset gpu with 2 replicas, but got %s with %d", gpu.Name, *gpu.Replicas)
+	}
+	if cluster.APIEndpoint.Host != "" || len(cluster.Hosts) != 0 {
+		t.Errorf("expected the API endpoint and hosts to be unchanged")
+	}
+
+	if err := c.ApplyToWorkerSets(cluster, []string{"missing"}); err == nil {
+		t.Errorf("expected an error for a workerset missing in the terraform output")
+	}
+}
+
+func TestCloudProviderName(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name             string
+		config           *Config
+		clusterProvider  kubeonev1alpha1.CloudProviderName
+		expectedProvider kubeonev1alpha1.CloudProviderName
+	}{
+		{
+			name:             "terraform output takes precedence",
+			config:           testConfig("aws", 1, nil),
+			clusterProvider:  kubeonev1alpha1.CloudProviderNameHetzner,
+			expectedProvider: kubeonev1alpha1.CloudProviderNameAWS,
+		},
+		{
+			name:             "cluster config without terraform cloud provider",
+			config:           &Config{},
+			clusterProvider:  kubeonev1alpha1.CloudProviderNameHetzner,
+			expectedProvider: kubeonev1alpha1.CloudProviderNameHetzner,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			cluster := &kubeonev1alpha1.KubeOneCluster{}
+			cluster.CloudProvider.Name = tc.clusterProvider
+			if got := tc.config.cloudProviderName(cluster); got != tc.expectedProvider {
+				t.Fatalf("expected provider %q, but got %q", tc.expectedProvider, got)
+			}
+
+			// ApplyToWorkerSets uses the same provider, e.g. sets the AWS
+			// disk type
+			c := tc.config
+			c.KubeOneWorkers.Value = map[string][]WorkerSetConfig{"pool": {WorkerSetConfig(`{"replicas": 1}`)}}
+			if err := c.ApplyToWorkerSets(cluster, []string{"pool"}); err != nil {
+				t.Fatal(err)
+			}
+			hasDiskType := strings.Contains(string(cluster.Workers[0].Config.CloudProviderSpec), `"diskType"`)
+			if hasDiskType != (tc.expectedProvider == kubeonev1alpha1.CloudProviderNameAWS) {
+				t.Fatalf("expected workerset to be updated for %q, but got spec %s", tc.expectedProvider, cluster.Workers[0].Config.CloudProviderSpec)
+			}
+		})
+	}
+}
+
+func TestAnnotateHosts(t *testing.T) {
+	t.Parallel()
+
+	c := testConfig("aws", 2, nil)
+	if err := c.AnnotateHosts(map[string]string{"example.com/rack": "r1", "maintenance-window": "sun"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AnnotateHosts(map[string]string{"example.com/rack": "r2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AnnotateHosts(map[string]string{"invalid key": "x"}); err == nil {
+		t.Fatalf("expected an error for an invalid annotation key")
+	}
+
+	cluster := &kubeonev1alpha1.KubeOneCluster{}
+	if err := c.Apply(cluster); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{"example.com/rack": "r2", "maintenance-window": "sun"}
+	for _, host := range cluster.Hosts {
+		if !reflect.DeepEqual(host.Annotations, expected) {
+			t.Errorf("expected annotations %v for host %s, but got %v", expected, host.PublicAddress, host.Annotations)
+		}
+	}
+
+	// the hosts don't share their annotations with each other or the config
+	cluster.Hosts[0].Annotations["example.com/rack"] = "changed"
+	if rack := cluster.Hosts[1].Annotations["example.com/rack"]; rack != "r2" {
+		t.Errorf("expected hosts not to share their annotations, but got %q", rack)
+	}
+	if host, err := c.ControlPlaneHostConfig(0); err != nil {
+		t.Fatal(err)
+	} else if rack := host.Annotations["example.com/rack"]; rack != "r2" {
+		t.Errorf("expected the config not to share its annotations, but got %q", rack)
+	}
+
+	given := map[string]string{"example.com/rack": "r3"}
+	hosts, err := c.ApplyToHostConfigs([]kubeonev1alpha1.HostConfig{
+		{Annotations: given},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rack := hosts[0].Annotations["example.com/rack"]; rack != "r3" {
+		t.Errorf("expected the annotation from config.yaml to take precedence, but got %q", rack)
+	}
+	if expected := map[string]string{"example.com/rack": "r3"}; !reflect.DeepEqual(given, expected) {
+		t.Errorf("expected the given annotations not to be modified, but got %v", given)
+	}
+}
+
+func TestUpdatePacketWorkersetUserData(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name         string
+		cfg          string
+		expectedSpec string
+		expectedErr  bool
+	}{
+		{
+			name:         "base64 encoded user data",
+			cfg:          `{"userData": "IyEvYmluL3NoCmVjaG8gZmlwcwo="}`,
+			expectedSpec: `{"userData":"#!/bin/sh\necho fips\n"}`,
+		},
+		{
+			name: "no user data",
+			cfg:  `{}`,
+		},
+		{
+			name:        "invalid base64",
+			cfg:         `{"userData": "#!/bin/sh"}`,
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := &Config{}
+			w := &kubeonev1alpha1.WorkerConfig{}
+			err := c.updatePacketWorkerset(w, json.RawMessage(tc.cfg))
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error %t, but got %v", tc.expectedErr, err)
+			}
+			if tc.expectedErr {
+				return
+			}
+			if string(w.Config.CloudProviderSpec) != tc.expectedSpec {
+				t.Fatalf("expected spec %s, but got %s", tc.expectedSpec, w.Config.CloudProviderSpec)
+			}
+		})
+	}
+}
+
+func TestConfigString(t *testing.T) {
+	t.Parallel()
+
+	c := testConfig("aws", 3, map[string]int{"pool-b": 5, "pool-a": 3})
+	c.KubeOneWorkers.Value["pool-c"] = []WorkerSetConfig{WorkerSetConfig(`{}`)}
+
+	expected := "Config{provider: aws, controlPlane: 3 hosts, workers: {pool-a: 3, pool-b: 5, pool-c: ?}}"
+	if s := fmt.Sprintf("%v", c); s != expected {
+		t.Errorf("expected %q, but got %q", expected, s)
+	}
+
+	expected = "Config{provider: unset, controlPlane: 0 hosts, workers: {}}"
+	if s := (&Config{}).String(); s != expected {
+		t.Errorf("expected %q, but got %q", expected, s)
+	}
+}
+
+func TestUpdateAWSWorkersetNetworkInterfaces(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name          string
+		cfg           string
+		expectedNICs  string
+		expectedUnset bool
+	}{
+		{
+			name:         "network interfaces",
+			cfg:          `{"networkInterfaces": [{"subnetId": "subnet-1", "securityGroupIDs": ["sg-1"], "deviceIndex": 1}]}`,
+			expectedNICs: `[{"deviceIndex":1,"securityGroupIDs":["sg-1"],"subnetId":"subnet-1"}]`,
+		},
+		{
+			name:          "no network interfaces",
+			cfg:           `{}`,
+			expectedUnset: true,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := &Config{}
+			w := &kubeonev1alpha1.WorkerConfig{}
+			if err := c.updateAWSWorkerset(w, json.RawMessage(tc.cfg)); err != nil {
+				t.Fatal(err)
+			}
+
+			var spec map[string]json.RawMessage
+			if err := json.Unmarshal(w.Config.CloudProviderSpec, &spec); err != nil {
+				t.Fatal(err)
+			}
+			nics, ok := spec["networkInterfaces"]
+			if ok == tc.expectedUnset {
+				t.Fatalf("expected networkInterfaces to be set %t, but got %s", !tc.expectedUnset, w.Config.CloudProviderSpec)
+			}
+			if ok && string(nics) != tc.expectedNICs {
+				t.Fatalf("expected network interfaces %s, but got %s", tc.expectedNICs, nics)
+			}
+		})
+	}
+}
+
+func TestUpdateAWSWorkersetSubnets(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name              string
+		cfg               string
+		expectedSubnetID  string
+		expectedSubnetIDs string
+		expectedError     bool
+	}{
+		{
+			name:             "single subnetId",
+			cfg:              `{"subnetId": "subnet-1"}`,
+			expectedSubnetID: `"subnet-1"`,
+		},
+		{
+			name:             "single subnetIds entry",
+			cfg:              `{"subnetIds": ["subnet-1"]}`,
+			expectedSubnetID: `"subnet-1"`,
+		},
+		{
+			name:              "multiple subnetIds",
+			cfg:               `{"subnetIds": ["subnet-1", "subnet-2"]}`,
+			expectedSubnetIDs: `["subnet-1","subnet-2"]`,
+		},
+		{
+			name:          "both subnetId and subnetIds",
+			cfg:           `{"subnetId": "subnet-1", "subnetIds": ["subnet-2", "subnet-3"]}`,
+			expectedError: true,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := &Config{}
+			w := &kubeonev1alpha1.WorkerConfig{}
+			err := c.updateAWSWorkerset(w, json.RawMessage(tc.cfg))
+			if (err != nil) != tc.expectedError {
+				t.Fatalf("expected error = %v, but got %v", tc.expectedError, err)
+			}
+			if tc.expectedError {
+				return
+			}
+
+			var spec map[string]json.RawMessage
+			if err := json.Unmarshal(w.Config.CloudProviderSpec, &spec); err != nil {
+				t.Fatal(err)
+			}
+			if got := string(spec["subnetId"]); got != tc.expectedSubnetID {
+				t.Errorf("expected subnetId %s, but got %s", tc.expectedSubnetID, got)
+			}
+			if got := string(spec["subnetIds"]); got != tc.expectedSubnetIDs {
+				t.Errorf("expected subnetIds %s, but got %s", tc.expectedSubnetIDs, got)
+			}
+		})
+	}
+}
+
+func TestApplyEtcdCompactionInterval(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name             string
+		configured       string
+		expectedInterval string
+	}{
+		{
+			name:             "not configured",
+			expectedInterval: "5m",
+		},
+		{
+			name:             "configured in config.yaml",
+			configured:       "1h",
+			expectedInterval: "1h",
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := testConfig("aws", 1, nil)
+			c.KubeOneHosts.Value.EtcdCompactionInterval = "5m"
+
+			cluster := &kubeonev1alpha1.KubeOneCluster{}
+			cluster.ComponentConfig.Etcd.CompactionInterval = tc.configured
+			if err := c.Apply(cluster); err != nil {
+				t.Fatal(err)
+			}
+			if got := cluster.ComponentConfig.Etcd.CompactionInterval; got != tc.expectedInterval {
+				t.Fatalf("expected compaction interval %q, but got %q", tc.expectedInterval, got)
+			}
+		})
+	}
+}
+
+func TestApplyPrivateNetworkGateway(t *testing.T) {
+	t.Parallel()
+
+	c := testConfig("aws", 2, nil)
+	c.KubeOneHosts.Value.ControlPlane[0].PrivateNetworkGateway = "10.0.0.254"
+
+	cluster := &kubeonev1alpha1.KubeOneCluster{}
+	if err := c.Apply(cluster); err != nil {
+		t.Fatal(err)
+	}
+	for _, host := range cluster.Hosts {
+		if host.PrivateNetworkGateway != "10.0.0.254" {
+			t.Fatalf("expected private network gateway %q, but got %q", "10.0.0.254", host.PrivateNetworkGateway)
+		}
+	}
+
+	// the gateway from `config.yaml` takes precedence
+	hosts := []kubeonev1alpha1.HostConfig{{PrivateNetworkGateway: "10.0.0.1"}}
+	updated, err := c.ApplyToHostConfigs(hosts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gw := updated[0].PrivateNetworkGateway; gw != "10.0.0.1" {
+		t.Fatalf("expected private network gateway %q, but got %q", "10.0.0.1", gw)
+	}
+	if gw := updated[1].PrivateNetworkGateway; gw != "10.0.0.254" {
+		t.Fatalf("expected private network gateway %q, but got %q", "10.0.0.254", gw)
+	}
+}
+
+func TestWorkerReplicaTotal(t *testing.T) {
+	t.Parallel()
+
+	unset := testConfig("aws", 1, map[string]int{"pool-a": 2})
+	unset.KubeOneWorkers.Value["pool-b"] = []WorkerSetConfig{WorkerSetConfig(`{"instanceType": "t3.medium"}`)}
+
+	invalid := testConfig("aws", 1, nil)
+	invalid.KubeOneWorkers.Value["pool"] = []WorkerSetConfig{WorkerSetConfig(`{"replicas": "3"}`)}
+
+	testcases := []struct {
+		name          string
+		config        *Config
+		expectedTotal int
+		expectedErr   error
+		expectedError bool
+	}{
+		{
+			name:          "replicas of all workersets",
+			config:        testConfig("aws", 1, map[string]int{"pool-a": 2, "pool-b": 3}),
+			expectedTotal: 5,
+		},
+		{
+			name:          "no workersets",
+			config:        testConfig("aws", 1, nil),
+			expectedErr:   ErrReplicasUnset,
+			expectedError: true,
+		},
+		{
+			name:          "workerset without replicas",
+			config:        unset,
+			expectedErr:   ErrReplicasUnset,
+			expectedError: true,
+		},
+		{
+			name:          "invalid replicas",
+			config:        invalid,
+			expectedError: true,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			total, err := tc.config.WorkerReplicaTotal()
+			if (err != nil) != tc.expectedError {
+				t.Fatalf("expected error %t, but got %v", tc.expectedError, err)
+			}
+			if tc.expectedErr != nil && errors.Cause(err) != tc.expectedErr {
+				t.Fatalf("expected error cause %v, but got %v", tc.expectedErr, err)
+			}
+			if total != tc.expectedTotal {
+				t.Fatalf("expected total %d, but got %d", tc.expectedTotal, total)
+			}
+		})
+	}
+}
+
+func TestUpdateGCEWorkersetDiskEncryptionKey(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name         string
+		cfg          string
+		existingSpec string
+		expectedSpec string
+	}{
+		{
+			name:         "kms key name",
+			cfg:          `{"diskEncryptionKMSKey": "projects/p/locations/l/keyRings/r/cryptoKeys/k"}`,
+			expectedSpec: `{"diskEncryptionKey":{"kmsKeyName":"projects/p/locations/l/keyRings/r/cryptoKeys/k"},"preemptible":false}`,
+		},
+		{
+			name:         "no kms key name",
+			cfg:          `{}`,
+			expectedSpec: `{"preemptible":false}`,
+		},
+		{
+			name:         "configured in config.yaml",
+			cfg:          `{"diskEncryptionKMSKey": "projects/p/locations/l/keyRings/r/cryptoKeys/k"}`,
+			existingSpec: `{"diskEncryptionKey":{"kmsKeyName":"other"}}`,
+			expectedSpec: `{"diskEncryptionKey":{"kmsKeyName":"other"},"preemptible":false}`,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := &Config{}
+			w := &kubeonev1alpha1.WorkerConfig{}
+			if tc.existingSpec != "" {
+				w.Config.CloudProviderSpec = json.RawMessage(tc.existingSpec)
+			}
+			if err := c.updateGCEWorkerset(w, json.RawMessage(tc.cfg)); err != nil {
+				t.Fatal(err)
+			}
+			if string(w.Config.CloudProviderSpec) != tc.expectedSpec {
+				t.Fatalf("expected spec %s, but got %s", tc.expectedSpec, w.Config.CloudProviderSpec)
+			}
+		})
+	}
+}
+
+func TestApplyResetsWarnings(t *testing.T) {
+	t.Parallel()
+
+	c := testConfig("aws", 1, nil)
+	c.KubeOneWorkers.Value["pool"] = []WorkerSetConfig{
+		WorkerSetConfig(`{"replicas": 1, "operatingSystemSpec": [{"distUpgradeOnBoot": true}, {"distUpgradeOnBoot": false}]}`),
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := c.Apply(&kubeonev1alpha1.KubeOneCluster{}); err != nil {
+			t.Fatal(err)
+		}
+		if len(c.Warnings()) != 1 {
+			t.Fatalf("expected 1 warning after apply %d, but got %v", i+1, c.Warnings())
+		}
+	}
+}
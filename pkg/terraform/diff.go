@@ -0,0 +1,231 @@
+/*
+Copyright 2019 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terraform
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	kubeonev1alpha1 "github.com/kubermatic/kubeone/pkg/apis/kubeone/v1alpha1"
+)
+
+// ConfigChangeset describes the differences between two terraform outputs
+type ConfigChangeset struct {
+	AddedControlPlaneHosts   []string
+	RemovedControlPlaneHosts []string
+	AddedWorkerSets          []string
+	RemovedWorkerSets        []string
+	// ChangedWorkerSets maps workerset names to the fields that changed
+	ChangedWorkerSets map[string][]FieldChange
+}
+
+// FieldChange describes a changed workerset field
+type FieldChange struct {
+	Field  string
+	Before interface{}
+	After  interface{}
+}
+
+// ConfigDiff compares two terraform outputs. Control plane hosts are compared
+// by their public address, workersets by their name.
+func ConfigDiff(before, after *Config) (ConfigChangeset, error) {
+	if before == nil {
+		before = &Config{}
+	}
+	if after == nil {
+		after = &Config{}
+	}
+
+	cs := ConfigChangeset{
+		ChangedWorkerSets: map[string][]FieldChange{},
+	}
+
+	beforeHosts, _ := before.ControlPlaneIPs()
+	afterHosts, _ := after.ControlPlaneIPs()
+	cs.AddedControlPlaneHosts = stringsDifference(afterHosts, beforeHosts)
+	cs.RemovedControlPlaneHosts = stringsDifference(beforeHosts, afterHosts)
+
+	beforeWorkerSets := before.WorkerSetNames()
+	afterWorkerSets := after.WorkerSetNames()
+	cs.AddedWorkerSets = stringsDifference(afterWorkerSets, beforeWorkerSets)
+	cs.RemovedWorkerSets = stringsDifference(beforeWorkerSets, afterWorkerSets)
+
+	for _, name := range afterWorkerSets {
+		if !containsString(beforeWorkerSets, name) {
+			continue
+		}
+
+		beforeSpec, err := workerSetSpec(before, name)
+		if err != nil {
+			return cs, err
+		}
+		afterSpec, err := workerSetSpec(after, name)
+		if err != nil {
+			return cs, err
+		}
+
+		if changes := diffWorkerSetSpecs(beforeSpec, afterSpec); len(changes) > 0 {
+			cs.ChangedWorkerSets[name] = changes
+		}
+	}
+
+	return cs, nil
+}
+
+// WorkerDiff describes how applying the terraform output changes the
+// workersets of a cluster
+type WorkerDiff struct {
+	// Created are workersets only present in the terraform output
+	Created []string
+	// Merged are existing workersets changed by the terraform output
+	Merged []string
+	// Unchanged are existing workersets not changed by the terraform output
+	Unchanged []string
+	// RemovedFromCluster are workersets only present in the cluster config
+	RemovedFromCluster []string
+}
+
+// WorkerSetDiff returns how Apply would change the workersets of the given
+// cluster, without modifying it
+func (c *Config) WorkerSetDiff(cluster *kubeonev1alpha1.KubeOneCluster) (WorkerDiff, error) {
+	var diff WorkerDiff
+
+	provider := c.cloudProviderName(cluster)
+
+	// don't collect the warnings of the dry run in c
+	scratch := *c
+	scratch.warnings = nil
+
+	names := c.WorkerSetNames()
+	for _, name := range names {
+		ws, ok := c.WorkerSetByName(name)
+		if !ok {
+			continue
+		}
+		value := json.RawMessage(ws)
+
+		var existing *kubeonev1alpha1.WorkerConfig
+		for i := range cluster.Workers {
+			if cluster.Workers[i].Name == name {
+				existing = &cluster.Workers[i]
+				break
+			}
+		}
+		if existing == nil {
+			diff.Created = append(diff.Created, name)
+			continue
+		}
+
+		updateProviderWorkerset := scratch.providerWorkersetUpdater(provider)
+		if updateProviderWorkerset == nil {
+			return diff, errors.Errorf("unknown provider %v", provider)
+		}
+
+		updated := existing.DeepCopy()
+		if err := updateProviderWorkerset(updated, value); err != nil {
+			return diff, errors.Wrapf(err, "failed to update provider-specific config for workerset %q", name)
+		}
+		if err := scratch.updateCommonWorkerConfig(updated, value); err != nil {
+			return diff, errors.Wrapf(err, "failed to update common config for workerset %q", name)
+		}
+
+		equal, err := equalWorkerConfigs(existing, updated)
+		if err != nil {
+			return diff, errors.Wrapf(err, "failed to compare workerset %q", name)
+		}
+		if equal {
+			diff.Unchanged = append(diff.Unchanged, name)
+		} else {
+			diff.Merged = append(diff.Merged, name)
+		}
+	}
+
+	for _, w := range cluster.Workers {
+		if !containsString(names, w.Name) {
+			diff.RemovedFromCluster = append(diff.RemovedFromCluster, w.Name)
+		}
+	}
+
+	return diff, nil
+}
+
+// equalWorkerConfigs compares two workersets ignoring the formatting and key
+// order of their raw JSON specs
+func equalWorkerConfigs(a, b *kubeonev1alpha1.WorkerConfig) (bool, error) {
+	var values [2]interface{}
+	for i, w := range []*kubeonev1alpha1.WorkerConfig{a, b} {
+		buf, err := json.Marshal(w)
+		if err != nil {
+			return false, err
+		}
+		if err = json.Unmarshal(buf, &values[i]); err != nil {
+			return false, err
+		}
+	}
+
+	return reflect.DeepEqual(values[0], values[1]), nil
+}
+
+func workerSetSpec(c *Config, name string) (map[string]interface{}, error) {
+	spec := map[string]interface{}{}
+
+	ws, ok := c.FindWorkerSet(name)
+	if !ok {
+		return spec, nil
+	}
+	if err := json.Unmarshal(ws.Spec, &spec); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse workerset %q", name)
+	}
+
+	return spec, nil
+}
+
+func diffWorkerSetSpecs(before, after map[string]interface{}) []FieldChange {
+	fields := map[string]struct{}{}
+	for k := range before {
+		fields[k] = struct{}{}
+	}
+	for k := range after {
+		fields[k] = struct{}{}
+	}
+
+	var changes []FieldChange
+	for field := range fields {
+		if !reflect.DeepEqual(before[field], after[field]) {
+			changes = append(changes, FieldChange{Field: field, Before: before[field], After: after[field]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+
+	return changes
+}
+
+// stringsDifference returns the items of a that are not in b
+func stringsDifference(a, b []string) []string {
+	var diff []string
+	for _, item := range a {
+		if !containsString(b, item) {
+			diff = append(diff, item)
+		}
+	}
+	return diff
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2019 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terraform
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	kubeonev1alpha1 "github.com/kubermatic/kubeone/pkg/apis/kubeone/v1alpha1"
+)
+
+func TestConfigDiff(t *testing.T) {
+	t.Parallel()
+
+	before := testConfig("aws", 2, nil)
+	before.KubeOneWorkers.Value = map[string][]WorkerSetConfig{
+		"pool-a": {WorkerSetConfig(`{"replicas": 1, "instanceType": "t3.medium"}`)},
+		"pool-b": {WorkerSetConfig(`{"replicas": 1}`)},
+	}
+	after := testConfig("aws", 3, nil)
+	after.KubeOneHosts.Value.ControlPlane[0].PublicAddress[0] = "192.0.2.100"
+	after.KubeOneWorkers.Value = map[string][]WorkerSetConfig{
+		"pool-a": {WorkerSetConfig(`{"replicas": 3, "instanceType": "t3.medium", "diskSize": 50}`)},
+		"pool-c": {WorkerSetConfig(`{"replicas": 1}`)},
+	}
+
+	cs, err := ConfigDiff(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := ConfigChangeset{
+		AddedControlPlaneHosts:   []string{"192.0.2.100", "192.0.2.3"},
+		RemovedControlPlaneHosts: []string{"192.0.2.1"},
+		AddedWorkerSets:          []string{"pool-c"},
+		RemovedWorkerSets:        []string{"pool-b"},
+		ChangedWorkerSets: map[string][]FieldChange{
+			"pool-a": {
+				{Field: "diskSize", After: float64(50)},
+				{Field: "replicas", Before: float64(1), After: float64(3)},
+			},
+		},
+	}
+	if !reflect.DeepEqual(cs, expected) {
+		t.Fatalf("expected changeset %+v, but got %+v", expected, cs)
+	}
+}
+
+func TestConfigDiffInvalidWorkerSet(t *testing.T) {
+	t.Parallel()
+
+	before := testConfig("aws", 1, map[string]int{"pool": 1})
+	after := testConfig("aws", 1, nil)
+	after.KubeOneWorkers.Value = map[string][]WorkerSetConfig{
+		"pool": {WorkerSetConfig(`[]`)},
+	}
+
+	if _, err := ConfigDiff(before, after); err == nil {
+		t.Fatal("expected an error, but got nil")
+	}
+}
+
+func TestWorkerSetDiff(t *testing.T) {
+	t.Parallel()
+
+	c := testConfig("aws", 1, nil)
+	c.KubeOneWorkers.Value = map[string][]WorkerSetConfig{
+		"created":   {WorkerSetConfig(`{"replicas": 1}`)},
+		"merged":    {WorkerSetConfig(`{"replicas": 1, "region": "eu-central-1"}`)},
+		"unchanged": {WorkerSetConfig(`{"region": "eu-central-1"}`)},
+	}
+
+	replicas := 2
+	cluster := &kubeonev1alpha1.KubeOneCluster{
+		Workers: []kubeonev1alpha1.WorkerConfig{
+			{Name: "merged", Replicas: &replicas},
+			{Name: "unchanged"},
+			{Name: "removed"},
+		},
+	}
+	cluster.Workers[1].Config.CloudProviderSpec = json.RawMessage(`{ "region": "eu-central-1", "diskType": "gp2" }`)
+
+	diff, err := c.WorkerSetDiff(cluster)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := WorkerDiff{
+		Created:            []string{"created"},
+		Merged:             []string{"merged"},
+		Unchanged:          []string{"unchanged"},
+		RemovedFromCluster: []string{"removed"},
+	}
+	if !reflect.DeepEqual(diff, expected) {
+		t.Fatalf("expected diff %+v, but got %+v", expected, diff)
+	}
+	if cluster.Workers[0].Config.CloudProviderSpec != nil {
+		t.Fatalf("expected cluster not to be modified, but got %s", cluster.Workers[0].Config.CloudProviderSpec)
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2019 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terraform
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+// Flatten returns the config as a flat map of dot-separated paths to values,
+// to be used as variables in manifest templates. List items are addressed by
+// their index, e.g. `control_plane.0.public_address.0`, and workersets by
+// their name, e.g. `workers.mypool.replicas`.
+func (c *Config) Flatten() map[string]interface{} {
+	flat := map[string]interface{}{}
+
+	section := func(prefix string, v interface{}) {
+		buf, err := json.Marshal(v)
+		if err != nil {
+			return
+		}
+
+		var value interface{}
+		dec := json.NewDecoder(bytes.NewReader(buf))
+		dec.UseNumber()
+		if err = dec.Decode(&value); err != nil {
+			return
+		}
+
+		flattenJSONValue(flat, prefix, value)
+	}
+
+	section("api", c.KubeOneAPI.Value)
+	section("", c.KubeOneHosts.Value)
+	section("ssh_host_keys", c.KubeOneSSHHostKeys.Value)
+	section("cert_sans", c.KubeOneCertSANs.Value)
+
+	for name := range c.KubeOneWorkers.Value {
+		if spec, ok := c.WorkerSetByName(name); ok {
+			section("workers."+name, spec)
+		}
+	}
+
+	return flat
+}
+
+// flattenJSONValue adds the leaf values of a generic JSON value to the given
+// map, keyed by their path below the prefix
+func flattenJSONValue(flat map[string]interface{}, prefix string, v interface{}) {
+	key := func(k string) string {
+		if prefix == "" {
+			return k
+		}
+		return prefix + "." + k
+	}
+
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			flattenJSONValue(flat, key(k), item)
+		}
+	case []interface{}:
+		for i, item := range val {
+			flattenJSONValue(flat, key(strconv.Itoa(i)), item)
+		}
+	case nil:
+	default:
+		flat[prefix] = val
+	}
+}
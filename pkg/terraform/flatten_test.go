@@ -0,0 +1,57 @@
+/*
+Copyright 2019 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terraform
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFlatten(t *testing.T) {
+	t.Parallel()
+
+	c := testConfig("aws", 2, map[string]int{"mypool": 3})
+	c.KubeOneAPI.Value.Endpoint = "lb.example.com"
+	c.KubeOneHosts.Value.PodCIDR = "10.244.0.0/16"
+	c.KubeOneWorkers.Value["other"] = []WorkerSetConfig{
+		WorkerSetConfig(`{"replicas": 1, "labels": {"team": "a"}, "sshPublicKeys": ["ssh-rsa AAA"]}`),
+	}
+
+	flat := c.Flatten()
+
+	expected := map[string]interface{}{
+		"api.endpoint":                      "lb.example.com",
+		"pod_cidr":                          "10.244.0.0/16",
+		"control_plane.0.cluster_name":      "test",
+		"control_plane.0.public_address.0":  "192.0.2.1",
+		"control_plane.0.public_address.1":  "192.0.2.2",
+		"control_plane.0.private_address.1": "10.0.0.2",
+		"workers.mypool.replicas":           json.Number("3"),
+		"workers.other.labels.team":         "a",
+		"workers.other.sshPublicKeys.0":     "ssh-rsa AAA",
+	}
+	for key, value := range expected {
+		if got, ok := flat[key]; !ok || got != value {
+			t.Errorf("expected %s to be %v, but got %v", key, value, got)
+		}
+	}
+
+	// nil values, such as the unset octavia flag, are left out
+	if _, ok := flat["openstack.use_octavia"]; ok {
+		t.Errorf("expected unset values to be left out")
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2019 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terraform
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const redactedValue = "<redacted>"
+
+// sensitiveSuffixes are the suffixes of field names holding sensitive values
+var sensitiveSuffixes = []string{"file", "key", "token", "secret", "password", "credential"}
+
+// Redact returns a deep copy of the config with all sensitive values, such as
+// file paths, keys, tokens, secrets, passwords and credentials, replaced, so
+// it is safe to be logged
+func (c *Config) Redact() (*Config, error) {
+	buf, err := json.Marshal(c)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal terraform output")
+	}
+
+	redacted := &Config{}
+	if err = json.Unmarshal(buf, redacted); err != nil {
+		return nil, errors.Wrap(err, "failed to copy terraform output")
+	}
+
+	redactStruct(reflect.ValueOf(redacted).Elem())
+
+	for name, values := range redacted.KubeOneWorkers.Value {
+		for i, value := range values {
+			var v interface{}
+			if err := json.Unmarshal(value, &v); err != nil {
+				return nil, errors.Wrapf(err, "failed to parse workerset %q", name)
+			}
+			// don't escape the angle brackets of the redacted value
+			var buf bytes.Buffer
+			enc := json.NewEncoder(&buf)
+			enc.SetEscapeHTML(false)
+			if err := enc.Encode(redactJSONValue(v)); err != nil {
+				return nil, errors.Wrapf(err, "failed to marshal workerset %q", name)
+			}
+			redacted.KubeOneWorkers.Value[name][i] = bytes.TrimSpace(buf.Bytes())
+		}
+	}
+
+	return redacted, nil
+}
+
+func isSensitive(name string) bool {
+	name = strings.ToLower(name)
+	for _, suffix := range sensitiveSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactStruct replaces the non-empty sensitive string fields and the values
+// of sensitive keys of string maps, such as `tls-private-key-file` in the
+// extra args, of the given struct value, recursing into nested structs and
+// slices of structs
+func redactStruct(v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+
+		switch field.Kind() {
+		case reflect.String:
+			if field.Len() > 0 && isSensitive(v.Type().Field(i).Name) {
+				field.SetString(redactedValue)
+			}
+		case reflect.Struct:
+			redactStruct(field)
+		case reflect.Map:
+			if field.Type().Key().Kind() != reflect.String || field.Type().Elem().Kind() != reflect.String {
+				continue
+			}
+			for _, key := range field.MapKeys() {
+				if isSensitive(key.String()) && field.MapIndex(key).Len() > 0 {
+					field.SetMapIndex(key, reflect.ValueOf(redactedValue).Convert(field.Type().Elem()))
+				}
+			}
+		case reflect.Slice:
+			for j := 0; j < field.Len(); j++ {
+				if field.Index(j).Kind() == reflect.Struct {
+					redactStruct(field.Index(j))
+				}
+			}
+		}
+	}
+}
+
+// redactJSONValue replaces the values of sensitive keys in a generic JSON value
+func redactJSONValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			if isSensitive(k) {
+				val[k] = redactedValue
+				continue
+			}
+			val[k] = redactJSONValue(item)
+		}
+	case []interface{}:
+		for i, item := range val {
+			val[i] = redactJSONValue(item)
+		}
+	}
+
+	return v
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2019 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terraform
+
+import (
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	t.Parallel()
+
+	c := testConfig("openstack", 1, nil)
+	c.KubeOneHosts.Value.ControlPlane[0].SSHPrivateKeyFile = "/home/user/.ssh/id_rsa"
+	c.KubeOneHosts.Value.APIServerExtraArgs = map[string]string{
+		"tls-private-key-file": "/etc/kubernetes/pki/apiserver.key",
+		"feature-gates":        "A=true",
+	}
+	c.KubeOneHosts.Value.ControllerManagerExtraArgs = map[string]string{"service-account-private-key-file": "/etc/kubernetes/pki/sa.key"}
+	c.KubeOneWorkers.Value = map[string][]WorkerSetConfig{
+		"pool": {WorkerSetConfig(`{"replicas": 1, "applicationCredentialSecret": "s3cr3t", "network": {"apiToken": "t0k3n"}}`)},
+	}
+
+	redacted, err := c.Redact()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if key := redacted.KubeOneHosts.Value.ControlPlane[0].SSHPrivateKeyFile; key != redactedValue {
+		t.Errorf("expected ssh private key file to be redacted, but got %q", key)
+	}
+	if user := redacted.KubeOneHosts.Value.ControlPlane[0].SSHUser; user != "root" {
+		t.Errorf("expected ssh user to be kept, but got %q", user)
+	}
+	if arg := redacted.KubeOneHosts.Value.APIServerExtraArgs["tls-private-key-file"]; arg != redactedValue {
+		t.Errorf("expected API server key file to be redacted, but got %q", arg)
+	}
+	if arg := redacted.KubeOneHosts.Value.APIServerExtraArgs["feature-gates"]; arg != "A=true" {
+		t.Errorf("expected API server feature gates to be kept, but got %q", arg)
+	}
+	if arg := redacted.KubeOneHosts.Value.ControllerManagerExtraArgs["service-account-private-key-file"]; arg != redactedValue {
+		t.Errorf("expected controller-manager key file to be redacted, but got %q", arg)
+	}
+	expectedSpec := `{"applicationCredentialSecret":"<redacted>","network":{"apiToken":"<redacted>"},"replicas":1}`
+	if spec := string(redacted.KubeOneWorkers.Value["pool"][0]); spec != expectedSpec {
+		t.Errorf("expected workerset spec %s, but got %s", expectedSpec, spec)
+	}
+
+	// the original config must not be modified
+	if key := c.KubeOneHosts.Value.ControlPlane[0].SSHPrivateKeyFile; key != "/home/user/.ssh/id_rsa" {
+		t.Errorf("expected original ssh private key file to be kept, but got %q", key)
+	}
+	if arg := c.KubeOneHosts.Value.APIServerExtraArgs["tls-private-key-file"]; arg != "/etc/kubernetes/pki/apiserver.key" {
+		t.Errorf("expected original API server key file to be kept, but got %q", arg)
+	}
+
+	c.KubeOneWorkers.Value["pool"] = []WorkerSetConfig{WorkerSetConfig(`{invalid`)}
+	if _, err = c.Redact(); err == nil {
+		t.Error("expected an error for an invalid workerset spec")
+	}
+}
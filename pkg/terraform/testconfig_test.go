@@ -0,0 +1,47 @@
+/*
+Copyright 2019 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terraform
+
+import (
+	"fmt"
+)
+
+// testConfig returns a minimal valid Config to be used as a baseline in tests.
+// It has the given number of control plane hosts on the given cloud provider
+// and a workerset for each entry of workerSets with the given replicas.
+func testConfig(provider string, hosts int, workerSets map[string]int) *Config {
+	cp := controlPlane{
+		ClusterName:   "test",
+		CloudProvider: &provider,
+		SSHUser:       "root",
+	}
+	for i := 0; i < hosts; i++ {
+		cp.PublicAddress = append(cp.PublicAddress, fmt.Sprintf("192.0.2.%d", i+1))
+		cp.PrivateAddress = append(cp.PrivateAddress, fmt.Sprintf("10.0.0.%d", i+1))
+	}
+
+	c := &Config{}
+	c.KubeOneHosts.Value.ControlPlane = []controlPlane{cp}
+	c.KubeOneWorkers.Value = make(map[string][]WorkerSetConfig, len(workerSets))
+	for name, replicas := range workerSets {
+		c.KubeOneWorkers.Value[name] = []WorkerSetConfig{
+			WorkerSetConfig(fmt.Sprintf(`{"replicas": %d}`, replicas)),
+		}
+	}
+
+	return c
+}
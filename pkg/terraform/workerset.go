@@ -0,0 +1,97 @@
+/*
+Copyright 2019 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terraform
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// WorkerSetConfig is the config of a single workerset as given in the
+// terraform output. It holds the common config, such as replicas and SSH
+// public keys, as well as the provider-specific config. The accessors decode
+// the config on every call, so callers needing a value repeatedly should keep
+// it.
+type WorkerSetConfig json.RawMessage
+
+// MarshalJSON returns the config as is
+func (w WorkerSetConfig) MarshalJSON() ([]byte, error) {
+	return json.RawMessage(w).MarshalJSON()
+}
+
+// UnmarshalJSON sets the config to a copy of data
+func (w *WorkerSetConfig) UnmarshalJSON(data []byte) error {
+	return (*json.RawMessage)(w).UnmarshalJSON(data)
+}
+
+// GetCloudProviderSpec returns the provider-specific part of the config, i.e.
+// all fields but the common ones
+func (w WorkerSetConfig) GetCloudProviderSpec() (json.RawMessage, error) {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(w, &fields); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal workerset config")
+	}
+
+	for _, key := range commonWorkerConfigKeys() {
+		delete(fields, key)
+	}
+
+	return json.Marshal(fields)
+}
+
+// GetSSHPublicKeys returns the SSH public keys of the workerset
+func (w WorkerSetConfig) GetSSHPublicKeys() ([]string, error) {
+	cc, err := w.common()
+	if err != nil {
+		return nil, err
+	}
+
+	return cc.SSHPublicKeys, nil
+}
+
+// GetReplicas returns the replicas of the workerset, or nil if not set
+func (w WorkerSetConfig) GetReplicas() (*int, error) {
+	cc, err := w.common()
+	if err != nil {
+		return nil, err
+	}
+
+	return cc.Replicas, nil
+}
+
+func (w WorkerSetConfig) common() (*commonWorkerConfig, error) {
+	var cc commonWorkerConfig
+	if err := json.Unmarshal(w, &cc); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal common worker config")
+	}
+
+	return &cc, nil
+}
+
+// commonWorkerConfigKeys returns the JSON keys of the common worker config
+func commonWorkerConfigKeys() []string {
+	t := reflect.TypeOf(commonWorkerConfig{})
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		keys = append(keys, strings.Split(t.Field(i).Tag.Get("json"), ",")[0])
+	}
+
+	return keys
+}
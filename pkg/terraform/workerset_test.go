@@ -0,0 +1,105 @@
+/*
+Copyright 2019 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terraform
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestWorkerSetConfigAccessors(t *testing.T) {
+	t.Parallel()
+
+	replicas := 3
+
+	testcases := []struct {
+		name                 string
+		config               WorkerSetConfig
+		expectedSpec         string
+		expectedSSHKeys      []string
+		expectedReplicas     *int
+		expectedError        bool
+		expectedReplicaError bool
+	}{
+		{
+			name:             "common and provider-specific config",
+			config:           WorkerSetConfig(`{"replicas": 3, "sshPublicKeys": ["ssh-rsa AAA"], "region": "eu-central-1", "ami": "ami-123"}`),
+			expectedSpec:     `{"ami":"ami-123","region":"eu-central-1"}`,
+			expectedSSHKeys:  []string{"ssh-rsa AAA"},
+			expectedReplicas: &replicas,
+		},
+		{
+			name:         "empty config",
+			config:       WorkerSetConfig(`{}`),
+			expectedSpec: `{}`,
+		},
+		{
+			name:          "invalid config",
+			config:        WorkerSetConfig(`[]`),
+			expectedError: true,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			spec, err := tc.config.GetCloudProviderSpec()
+			if (err != nil) != tc.expectedError {
+				t.Fatalf("expected error %t, but got %v", tc.expectedError, err)
+			}
+			if string(spec) != tc.expectedSpec {
+				t.Fatalf("expected spec %s, but got %s", tc.expectedSpec, spec)
+			}
+
+			keys, err := tc.config.GetSSHPublicKeys()
+			if (err != nil) != tc.expectedError {
+				t.Fatalf("expected error %t, but got %v", tc.expectedError, err)
+			}
+			if !reflect.DeepEqual(keys, tc.expectedSSHKeys) {
+				t.Fatalf("expected ssh public keys %v, but got %v", tc.expectedSSHKeys, keys)
+			}
+
+			replicas, err := tc.config.GetReplicas()
+			if (err != nil) != tc.expectedError {
+				t.Fatalf("expected error %t, but got %v", tc.expectedError, err)
+			}
+			if !reflect.DeepEqual(replicas, tc.expectedReplicas) {
+				t.Fatalf("expected replicas %v, but got %v", tc.expectedReplicas, replicas)
+			}
+		})
+	}
+}
+
+func TestWorkerSetConfigJSON(t *testing.T) {
+	t.Parallel()
+
+	var value map[string][]WorkerSetConfig
+	if err := json.Unmarshal([]byte(`{"pool": [{"replicas": 1}]}`), &value); err != nil {
+		t.Fatal(err)
+	}
+	if spec := string(value["pool"][0]); spec != `{"replicas": 1}` {
+		t.Fatalf("expected the config to be kept as is, but got %s", spec)
+	}
+
+	buf, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"pool":[{"replicas":1}]}`; string(buf) != expected {
+		t.Fatalf("expected %s, but got %s", expected, buf)
+	}
+}
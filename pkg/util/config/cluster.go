@@ -21,6 +21,7 @@ import (
 	"os"
 
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 
 	"k8s.io/apimachinery/pkg/runtime"
 
@@ -62,7 +63,21 @@ func SourceKubeOneClusterFromTerraformOutput(terraformOutput []byte, cluster *ku
 	if err != nil {
 		return errors.Wrap(err, "failed to parse Terraform config")
 	}
-	return tfConfig.Apply(cluster)
+
+	// the control plane hosts may be given in the cluster config, in which
+	// case the hosts section of the terraform output is skipped, while the
+	// cluster-wide config is still applied
+	if !tfConfig.HasControlPlane() && len(cluster.Hosts) > 0 {
+		err = tfConfig.ApplyPartial(cluster, terraform.SectionAPI, terraform.SectionNetworking, terraform.SectionCluster, terraform.SectionWorkers)
+	} else {
+		err = tfConfig.Apply(cluster)
+	}
+
+	for _, warning := range tfConfig.Warnings() {
+		logrus.Warnf("terraform output: %s", warning)
+	}
+
+	return err
 }
 
 // DefaultedKubeOneCluster converts a versioned KubeOneCluster object to an internal representation of KubeOneCluster
@@ -0,0 +1,129 @@
+/*
+Copyright 2019 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+
+	kubeonev1alpha1 "github.com/kubermatic/kubeone/pkg/apis/kubeone/v1alpha1"
+)
+
+func TestSourceKubeOneClusterFromTerraformOutputWithoutControlPlane(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name            string
+		output          string
+		expectedWorkers int
+	}{
+		{
+			name: "without workersets",
+			output: `{
+				"kubeone_api": {"value": {"endpoint": "lb.example.com", "internal_endpoint": "10.0.0.100"}},
+				"kubeone_cert_sans": {"value": ["api.example.com"]},
+				"kubeone_hosts": {"value": {"pod_cidr": "10.244.0.0/16", "machine_controller_enabled": false}}
+			}`,
+		},
+		{
+			name: "with workersets",
+			output: `{
+				"kubeone_api": {"value": {"endpoint": "lb.example.com", "internal_endpoint": "10.0.0.100"}},
+				"kubeone_cert_sans": {"value": ["api.example.com"]},
+				"kubeone_hosts": {"value": {"pod_cidr": "10.244.0.0/16", "machine_controller_enabled": false}},
+				"kubeone_workers": {"value": {"pool": [{"replicas": 2}]}}
+			}`,
+			expectedWorkers: 1,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			hosts := []kubeonev1alpha1.HostConfig{{PublicAddress: "192.0.2.1", PrivateAddress: "10.0.0.1"}}
+			cluster := &kubeonev1alpha1.KubeOneCluster{
+				CloudProvider: kubeonev1alpha1.CloudProviderSpec{Name: kubeonev1alpha1.CloudProviderNameAWS},
+				Hosts:         hosts,
+			}
+			if err := SourceKubeOneClusterFromTerraformOutput([]byte(tc.output), cluster); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(cluster.Hosts) != 1 || cluster.Hosts[0].PublicAddress != "192.0.2.1" {
+				t.Errorf("expected the hosts from the cluster config to be kept, but got %+v", cluster.Hosts)
+			}
+			if cluster.APIEndpoint.Host != "lb.example.com" || cluster.APIEndpoint.InternalHost != "10.0.0.100" {
+				t.Errorf("expected the API endpoint from terraform output, but got %+v", cluster.APIEndpoint)
+			}
+			if len(cluster.APIEndpoint.AlternativeNames) != 1 || cluster.APIEndpoint.AlternativeNames[0] != "api.example.com" {
+				t.Errorf("expected the cert SANs from terraform output, but got %v", cluster.APIEndpoint.AlternativeNames)
+			}
+			if cluster.ClusterNetwork.PodSubnet != "10.244.0.0/16" {
+				t.Errorf("expected the pod subnet from terraform output, but got %q", cluster.ClusterNetwork.PodSubnet)
+			}
+			if cluster.MachineController == nil || cluster.MachineController.Deploy {
+				t.Errorf("expected machine-controller to be disabled, but got %+v", cluster.MachineController)
+			}
+			if len(cluster.Workers) != tc.expectedWorkers {
+				t.Errorf("expected %d workersets, but got %d", tc.expectedWorkers, len(cluster.Workers))
+			}
+		})
+	}
+}
+
+func TestSourceKubeOneClusterFromTerraformOutputClusterSettingsWithoutControlPlane(t *testing.T) {
+	t.Parallel()
+
+	output := `{
+		"kubeone_hosts": {"value": {
+			"apiserver_extra_args": {"feature-gates": "A=true"},
+			"controller_manager_extra_args": {"node-monitor-period": "2s"},
+			"audit_log_path": "/var/log/audit.log",
+			"etcd_data_dir": "/data/etcd",
+			"oidc_issuer_url": "https://issuer.example.com",
+			"external_cloud_provider": true,
+			"cloud_controller_manager_version": "v1.2.0"
+		}}
+	}`
+
+	hosts := []kubeonev1alpha1.HostConfig{{PublicAddress: "192.0.2.1", PrivateAddress: "10.0.0.1"}}
+	cluster := &kubeonev1alpha1.KubeOneCluster{
+		CloudProvider: kubeonev1alpha1.CloudProviderSpec{Name: kubeonev1alpha1.CloudProviderNameHetzner},
+		Hosts:         hosts,
+	}
+	if err := SourceKubeOneClusterFromTerraformOutput([]byte(output), cluster); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cluster.Hosts) != 1 || cluster.Hosts[0].PublicAddress != "192.0.2.1" {
+		t.Errorf("expected the hosts from the cluster config to be kept, but got %+v", cluster.Hosts)
+	}
+	apiServerArgs := cluster.ComponentConfig.APIServer.ExtraArgs
+	if apiServerArgs["feature-gates"] != "A=true" || apiServerArgs["audit-log-path"] != "/var/log/audit.log" {
+		t.Errorf("expected the API server extra args from terraform output, but got %v", apiServerArgs)
+	}
+	if got := cluster.ComponentConfig.ControllerManager.ExtraArgs["node-monitor-period"]; got != "2s" {
+		t.Errorf("expected the controller-manager extra args from terraform output, but got %q", got)
+	}
+	if cluster.ComponentConfig.Etcd.DataDir != "/data/etcd" {
+		t.Errorf("expected the etcd data dir from terraform output, but got %q", cluster.ComponentConfig.Etcd.DataDir)
+	}
+	if cluster.Features.OpenIDConnect == nil || cluster.Features.OpenIDConnect.Config.IssuerURL != "https://issuer.example.com" {
+		t.Errorf("expected OpenID Connect from terraform output, but got %+v", cluster.Features.OpenIDConnect)
+	}
+	if !cluster.CloudProvider.External || cluster.CloudProvider.CCMVersion != "v1.2.0" {
+		t.Errorf("expected the external CCM config from terraform output, but got %+v", cluster.CloudProvider)
+	}
+}
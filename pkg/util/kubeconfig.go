@@ -17,6 +17,9 @@ limitations under the License.
 package util
 
 import (
+	"net"
+	"strconv"
+
 	"github.com/pkg/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -24,6 +27,8 @@ import (
 	"github.com/kubermatic/kubeone/pkg/ssh"
 
 	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapiv1 "k8s.io/client-go/tools/clientcmd/api/v1"
+	"sigs.k8s.io/yaml"
 )
 
 // DownloadKubeconfig downloads Kubeconfig over SSH
@@ -47,9 +52,30 @@ func DownloadKubeconfig(cluster *kubeoneapi.KubeOneCluster) ([]byte, error) {
 		return nil, err
 	}
 
+	// admin.conf points to the internal endpoint, which is not necessarily
+	// reachable from where KubeOne runs
+	if cluster.APIEndpoint.InternalHost != "" {
+		return useExternalEndpoint([]byte(kubeconfig), cluster.APIEndpoint)
+	}
+
 	return []byte(kubeconfig), nil
 }
 
+// useExternalEndpoint replaces the API server address of all clusters in the
+// given kubeconfig with the external API endpoint
+func useExternalEndpoint(kubeconfig []byte, endpoint kubeoneapi.APIEndpoint) ([]byte, error) {
+	var config clientcmdapiv1.Config
+	if err := yaml.Unmarshal(kubeconfig, &config); err != nil {
+		return nil, errors.Wrap(err, "unable to parse kubeconfig")
+	}
+
+	for i := range config.Clusters {
+		config.Clusters[i].Cluster.Server = "https://" + net.JoinHostPort(endpoint.Host, strconv.Itoa(endpoint.Port))
+	}
+
+	return yaml.Marshal(config)
+}
+
 // BuildKubernetesClientset builds core kubernetes and apiextensions clientsets
 func BuildKubernetesClientset(ctx *Context) error {
 	ctx.Logger.Infoln("Building Kubernetes clientset…")
@@ -0,0 +1,103 @@
+/*
+Copyright 2019 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	kubeoneapi "github.com/kubermatic/kubeone/pkg/apis/kubeone"
+
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: kubernetes
+  cluster:
+    certificate-authority-data: Y2E=
+    server: https://10.0.0.100:6443
+- name: other
+  cluster:
+    server: https://10.0.0.101:6443
+contexts:
+- name: kubernetes-admin@kubernetes
+  context:
+    cluster: kubernetes
+    user: kubernetes-admin
+current-context: kubernetes-admin@kubernetes
+users:
+- name: kubernetes-admin
+  user:
+    token: secret
+`
+
+func TestUseExternalEndpoint(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name           string
+		endpoint       kubeoneapi.APIEndpoint
+		expectedServer string
+	}{
+		{
+			name:           "DNS name",
+			endpoint:       kubeoneapi.APIEndpoint{Host: "lb.example.com", Port: 6443, InternalHost: "10.0.0.100"},
+			expectedServer: "https://lb.example.com:6443",
+		},
+		{
+			name:           "IPv6 address",
+			endpoint:       kubeoneapi.APIEndpoint{Host: "2001:db8::1", Port: 6443, InternalHost: "10.0.0.100"},
+			expectedServer: "https://[2001:db8::1]:6443",
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			kubeconfig, err := useExternalEndpoint([]byte(testKubeconfig), tc.endpoint)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			config, err := clientcmd.Load(kubeconfig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, cluster := range config.Clusters {
+				if cluster.Server != tc.expectedServer {
+					t.Errorf("expected server of cluster %q to be %q, but got %q", name, tc.expectedServer, cluster.Server)
+				}
+			}
+			if ca := string(config.Clusters["kubernetes"].CertificateAuthorityData); ca != "ca" {
+				t.Errorf("expected the CA to be kept, but got %q", ca)
+			}
+			if token := config.AuthInfos["kubernetes-admin"].Token; token != "secret" {
+				t.Errorf("expected the credentials to be kept, but got %q", token)
+			}
+			if config.CurrentContext != "kubernetes-admin@kubernetes" {
+				t.Errorf("expected the current context to be kept, but got %q", config.CurrentContext)
+			}
+		})
+	}
+
+	endpoint := kubeoneapi.APIEndpoint{Host: "lb.example.com", Port: 6443, InternalHost: "10.0.0.100"}
+	if _, err := useExternalEndpoint([]byte("invalid"), endpoint); err == nil {
+		t.Error("expected an error for an invalid kubeconfig")
+	}
+}